@@ -1,30 +1,65 @@
 package lazydb
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/mattn/go-sqlite3"
 )
 
+// Number of pages to be copied in each step of online backup.
+const backupStepPages = 100
+
+// Waiting time before retry a backup step, when source database is locked.
+const backupStepWait = 10 * time.Millisecond
+
 // Create a backup of current database to given path.
 //
+// The backup is taken by SQLite online backup API, which produce a consistent
+// snapshot of database, even other connections are writing at the same time.
+// Committed data that still inside -wal/-journal file will also be included.
+//
 // This function will ignore backup directory setting.
 func (l *LazyDB) BackupTo(dest string) (err error) {
+	return l.BackupToContext(context.Background(), dest)
+}
+
+// Create a backup of current database to given path, same as BackupTo().
+//
+// The context is checked between each backup step. If context is cancelled,
+// the incomplete backup file will be removed.
+func (l *LazyDB) BackupToContext(ctx context.Context, dest string) (err error) {
+	// Prevent db is nil
+	if l.db == nil {
+		return ErrNilDatabase
+	}
+
 	// Create file to prevent directory not existing
 	err = createDbFile(dest)
 	if err != nil {
 		return err
 	}
 
-	err = copyFile(l.dbPath, dest)
-	return err
+	err = onlineBackup(ctx, l.db, dest, l.backupProgress)
+	if err != nil {
+		os.Remove(dest) // Incomplete backup is not usable
+		return err
+	}
+
+	return nil
 }
 
-// Start auto backup process. If version is latest (i.e. no need to update), then no auto backup will be performed.
-func (l *LazyDB) autoBackup(m *migrate.Migrate) (dest string, err error) {
+// Start auto backup process, before migrate database to target version.
+// If database is already in target version (i.e. no need to update),
+// or it is newly created, then no auto backup will be performed.
+func (l *LazyDB) autoBackup(ctx context.Context, m *migrate.Migrate, target uint) (dest string, err error) {
 	// Prevent backup directory is empty string
 	if l.backupDir == "" {
 		return "", nil // Consider as graceful return
@@ -45,19 +80,8 @@ func (l *LazyDB) autoBackup(m *migrate.Migrate) (dest string, err error) {
 	// Determine database is newly created or not
 	isNew := (err == migrate.ErrNilVersion)
 
-	// Get latest database schema version
-	latest, err := LargestSchemaVer(l.migrateFs, l.migrateDir)
-	if err != nil {
-		return "", err
-	}
-
-	// Ensure latest version in lazydb config
-	if l.schemaVersion > 0 && l.schemaVersion < latest {
-		latest = l.schemaVersion
-	}
-
-	// Not create backup if new database, or schema up to date
-	if latest <= current || isNew {
+	// Not create backup if new database, or schema already in target version
+	if target == current || isNew {
 		return "", nil // Consider as graceful return
 	}
 
@@ -65,26 +89,238 @@ func (l *LazyDB) autoBackup(m *migrate.Migrate) (dest string, err error) {
 	dest = defaultBackupPath(l.dbPath, l.backupDir)
 
 	// Backup
-	err = l.BackupTo(dest)
+	err = l.BackupToContext(ctx, dest)
 	if err != nil {
 		return "", err
 	}
 
+	// Remove outdated backups, failure is reported without stopping migration
+	_, err = l.PruneBackups()
+	if err != nil && l.retention.OnError != nil {
+		l.retention.OnError(err)
+	}
+
 	return dest, nil
 }
 
+// Remove old backups inside backup directory, by retention policy of LazyDB.
+// Removed backup paths will be returned, ordered from newest to oldest.
+//
+// If backup directory is not set, or no retention policy is set,
+// then this function has no effect.
+func (l *LazyDB) PruneBackups() (removed []string, err error) {
+	// No backup directory or retention policy, consider as graceful return
+	if l.backupDir == "" || !l.retention.limited() {
+		return nil, nil
+	}
+
+	backups, err := listBackups(l.dbPath, l.backupDir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var total int64
+
+	for idx, bk := range backups {
+		total += bk.size
+
+		// Most recent backup must be kept
+		if idx == 0 {
+			continue
+		}
+
+		// Check backup violate any policy
+		expired := (l.retention.KeepLast > 0 && idx >= l.retention.KeepLast) ||
+			(l.retention.MaxAge > 0 && now.Sub(bk.created) > l.retention.MaxAge) ||
+			(l.retention.MaxBytes > 0 && total > l.retention.MaxBytes)
+
+		if !expired {
+			continue
+		}
+
+		err = os.Remove(bk.path)
+		if err != nil {
+			return removed, err
+		}
+
+		total -= bk.size
+		removed = append(removed, bk.path)
+	}
+
+	return removed, nil
+}
+
+// Layout of timestamp inside backup filename.
+const backupTimeLayout = "20060102150405"
+
 // Get default absolute path to backup database.
 func defaultBackupPath(dbPath string, backupDir string) string {
 	// Prepare timestamp for backup
-	str := time.Now().Format("20060102150405")
+	str := time.Now().Format(backupTimeLayout)
+
+	// Get filename parts
+	base, ext := backupNameParts(dbPath)
 
+	// Perform backup
+	return filepath.Join(backupDir, base+"_bk_"+str+ext)
+}
+
+// Get original database name & extension, which used to construct backup filename.
+func backupNameParts(dbPath string) (base string, ext string) {
 	// Get ext
-	ext := filepath.Ext(dbPath)
+	ext = filepath.Ext(dbPath)
 
 	// Get original database name
-	base := filepath.Base(dbPath)
+	base = filepath.Base(dbPath)
 	base = strings.Replace(base, ext, "", 1)
 
-	// Perform backup
-	return filepath.Join(backupDir, base+"_bk_"+str+ext)
+	return base, ext
+}
+
+// Backup file that created by auto backup.
+type backupFile struct {
+	path    string    // Path of backup file
+	created time.Time // Time that backup created, parsed from filename
+	size    int64     // File size in bytes
+}
+
+// Get all backups of given database inside backup directory, ordered from newest to oldest.
+//
+// Only files that match the naming of defaultBackupPath() will be returned.
+// Not existing backup directory will return empty slice.
+func listBackups(dbPath string, backupDir string) ([]backupFile, error) {
+	entries, err := os.ReadDir(backupDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	base, ext := backupNameParts(dbPath)
+	prefix := base + "_bk_"
+
+	backups := make([]backupFile, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+
+		// Skip not related file
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		// Parse timestamp in filename
+		str := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		created, err := time.ParseInLocation(backupTimeLayout, str, time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		backups = append(backups, backupFile{
+			path:    filepath.Join(backupDir, name),
+			created: created,
+			size:    info.Size(),
+		})
+	}
+
+	// Sort by created time, newest first
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].created.After(backups[j].created)
+	})
+
+	return backups, nil
+}
+
+// Copy the main database of src into dest file, by SQLite online backup API.
+//
+// The backup is performed page by page. If progress is not nil,
+// it will be called after every step with remaining & total pages count.
+func onlineBackup(ctx context.Context, src *sql.DB, dest string, progress func(remaining, total int)) error {
+	// Open destination database
+	destDb, err := sql.Open(DatabaseType, dest)
+	if err != nil {
+		return err
+	}
+	defer destDb.Close()
+
+	return copyDatabase(ctx, destDb, src, progress)
+}
+
+// Copy the main database of src into dest database, by SQLite online backup API.
+func copyDatabase(ctx context.Context, destDb, src *sql.DB, progress func(remaining, total int)) error {
+	// Obtain dedicated connections, as backup must use same connection in all steps
+	destConn, err := destDb.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destRaw any) error {
+		return srcConn.Raw(func(srcRaw any) error {
+			destSqlite, ok := destRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected destination connection type %T", destRaw)
+			}
+
+			srcSqlite, ok := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected source connection type %T", srcRaw)
+			}
+
+			return stepBackup(ctx, destSqlite, srcSqlite, progress)
+		})
+	})
+}
+
+// Run backup from src connection to dest connection, until all pages are copied
+// or context is cancelled.
+func stepBackup(ctx context.Context, dest, src *sqlite3.SQLiteConn, progress func(remaining, total int)) error {
+	bk, err := dest.Backup("main", src, "main")
+	if err != nil {
+		return err
+	}
+
+	remaining := -1
+	for {
+		// Stop backup when context cancelled
+		if err := ctx.Err(); err != nil {
+			bk.Finish()
+			return err
+		}
+
+		done, err := bk.Step(backupStepPages)
+		if err != nil {
+			bk.Finish()
+			return err
+		}
+
+		// Report progress
+		if progress != nil {
+			progress(bk.Remaining(), bk.PageCount())
+		}
+
+		if done {
+			break
+		}
+
+		// Source database is busy/locked, wait a while before next step
+		if bk.Remaining() == remaining {
+			time.Sleep(backupStepWait)
+		}
+		remaining = bk.Remaining()
+	}
+
+	return bk.Finish()
 }
@@ -1,9 +1,13 @@
 package lazydb
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -165,3 +169,178 @@ func TestCreateAutoBackupVersionSpecified(t *testing.T) {
 	assert.Nilf(t, err, "Error when migrate")
 	assert.EqualValuesf(t, "", bk, "Unexpected backup location")
 }
+
+// Ensure backup contains committed data that still inside WAL file.
+func TestBackupToWal(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Track backup progress
+	steps := 0
+	db := New(
+		DbPath(filepath.Join(tmpDir, "wal.db")),
+		BackupProgress(func(remaining, total int) { steps++ }),
+	)
+
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+
+	// Keep data inside WAL file only
+	_, err = db.DB().Exec("PRAGMA journal_mode=WAL; PRAGMA wal_autocheckpoint=0;")
+	assert.Nilf(t, err, "Error when enable WAL")
+	createDummyTable(db.DB())
+
+	// Perform backup
+	dest := filepath.Join(tmpDir, "bk", "wal_bk.db")
+	err = db.BackupTo(dest)
+	assert.Nilf(t, err, "Error when backup")
+	assert.Greaterf(t, steps, 0, "Backup progress is not reported")
+
+	// Check backup content
+	bk := New(DbPath(dest))
+	err = bk.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect backup: ", err)
+	}
+	defer bk.Close()
+
+	var ct int
+	err = bk.DB().QueryRow("SELECT COUNT(*) FROM test_table").Scan(&ct)
+	assert.Nilf(t, err, "Error when query backup")
+	assert.EqualValuesf(t, 2, ct, "Unexpected row count in backup")
+}
+
+// Ensure backup not performed when database is not connected.
+func TestBackupToNilDb(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "nil.db")))
+
+	err := db.BackupTo(filepath.Join(t.TempDir(), "nil_bk.db"))
+	assert.ErrorIs(t, err, ErrNilDatabase)
+}
+
+// Test removal of old backups by retention policy.
+func TestPruneBackups(t *testing.T) {
+	// Test case type
+	type testCase struct {
+		policy RetentionPolicy // Retention policy to apply
+		want   []int           // Index of backups that remain, 0 is the newest
+	}
+
+	now := time.Now()
+
+	tests := []testCase{
+		// No policy
+		{RetentionPolicy{}, []int{0, 1, 2, 3}},
+		// Keep last N
+		{RetentionPolicy{KeepLast: 2}, []int{0, 1}},
+		{RetentionPolicy{KeepLast: 10}, []int{0, 1, 2, 3}},
+		// Max age
+		{RetentionPolicy{MaxAge: 90 * time.Minute}, []int{0, 1}},
+		// Max bytes, newest backup always kept
+		{RetentionPolicy{MaxBytes: 25}, []int{0, 1}},
+		{RetentionPolicy{MaxBytes: 1}, []int{0}},
+		// Multiple policy
+		{RetentionPolicy{KeepLast: 3, MaxAge: 90 * time.Minute}, []int{0, 1}},
+	}
+
+	for idx, tt := range tests {
+		dir := t.TempDir()
+
+		// Prepare backups, each with 10 bytes, created 1 hour apart
+		var paths []string
+		for i := 0; i < 4; i++ {
+			name := "data_bk_" + now.Add(-time.Duration(i)*time.Hour).Format("20060102150405") + ".db"
+			paths = append(paths, filepath.Join(dir, name))
+			os.WriteFile(paths[i], []byte("0123456789"), 0644)
+		}
+
+		// Prepare not related files
+		others := []string{
+			filepath.Join(dir, "data_bk_abc.db"),
+			filepath.Join(dir, "other_bk_"+now.Add(-5*time.Hour).Format("20060102150405")+".db"),
+		}
+		for _, path := range others {
+			os.WriteFile(path, []byte("0123456789"), 0644)
+		}
+
+		db := New(DbPath(filepath.Join(dir, "data.db")), BackupDir(dir), Retention(tt.policy))
+		_, err := db.PruneBackups()
+		assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err)
+
+		// Check remaining backups
+		for i, path := range paths {
+			assert.EqualValuesf(t, slices.Contains(tt.want, i), IsFileExist(path),
+				"Case %d: Unexpected existence of backup %d", idx, i)
+		}
+
+		// Not related file must be kept
+		for _, path := range others {
+			assert.Truef(t, IsFileExist(path), "Case %d: Not related file removed: %s", idx, path)
+		}
+	}
+}
+
+// Ensure migration is not stopped when old backups cannot be removed after auto backup.
+func TestAutoBackupPruneFailed(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "prune.db")
+	bkDir := filepath.Join(tmpDir, "bk")
+
+	_, err := createOutdatedDb(path)
+	if err != nil {
+		t.Fatal("Failed to create outdated db: ", err)
+	}
+
+	var pruneErr error
+	db := New(
+		DbPath(path),
+		Migrate(fsNormalTestV3, dirNormalTestV3),
+		BackupDir(bkDir),
+		Retention(RetentionPolicy{KeepLast: 1, OnError: func(err error) { pruneErr = err }}),
+		// Replace backup directory by file after backup completed, so listing backups will fail
+		BackupProgress(func(remaining, total int) {
+			if remaining == 0 {
+				os.Rename(bkDir, bkDir+"_moved")
+				os.WriteFile(bkDir, nil, 0644)
+			}
+		}),
+		Version(0),
+	)
+	err = db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+
+	bk, err := db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.NotNilf(t, pruneErr, "Prune error should be reported")
+	assert.Truef(t, IsFileExist(filepath.Join(bkDir+"_moved", filepath.Base(bk))), "Backup should be created")
+
+	version, dirty, _, _ := readSchemaVersion(db.DB())
+	assert.EqualValues(t, 3, version)
+	assert.False(t, dirty)
+}
+
+// Ensure backup is stopped and removed when context is cancelled.
+func TestBackupToContextCancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db := New(DbPath(filepath.Join(tmpDir, "ctx.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dest := filepath.Join(tmpDir, "bk", "ctx_bk.db")
+	err = db.BackupToContext(ctx, dest)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Falsef(t, IsFileExist(dest), "Backup should be removed when cancelled")
+}
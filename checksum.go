@@ -0,0 +1,167 @@
+package lazydb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Table that store checksum of applied migration scripts.
+const checksumTable = "lazydb_checksums"
+
+// Mismatch between recorded checksum of applied migration, and current script content.
+type ChecksumMismatch struct {
+	Version  uint   // Version of migration
+	File     string // Filename of up script
+	Recorded string // Checksum recorded when migration applied
+	Current  string // Checksum of current script, empty if script not exist
+}
+
+func (m ChecksumMismatch) String() string {
+	if m.Current == "" {
+		return fmt.Sprintf("version %d: script %s is removed", m.Version, m.File)
+	}
+	return fmt.Sprintf("version %d: script %s is modified after applied", m.Version, m.File)
+}
+
+// Error when any applied migration script is modified.
+type ChecksumError struct {
+	Mismatches []ChecksumMismatch
+}
+
+func (e *ChecksumError) Error() string {
+	msg := make([]string, 0, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		msg = append(msg, m.String())
+	}
+	return fmt.Sprintf("%v: %s", ErrChecksumMismatch, strings.Join(msg, "; "))
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// Get checksum of given script content.
+func scriptChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Compare recorded checksums with current migration scripts, when checksum tracking is enabled.
+//
+// If mismatch handler is set, it will be called for every mismatch and nil will be returned.
+// Otherwise *ChecksumError will be returned.
+func (l *LazyDB) verifyChecksums(ctx context.Context) error {
+	if !l.checksums {
+		return nil
+	}
+
+	// Skip when no checksum recorded
+	exist, err := tableExists(l.db, checksumTable)
+	if err != nil || !exist {
+		return err
+	}
+
+	rows, err := l.db.QueryContext(ctx, "SELECT version, file, checksum FROM "+checksumTable+" ORDER BY version")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	mismatches := make([]ChecksumMismatch, 0)
+	for rows.Next() {
+		var m ChecksumMismatch
+		err = rows.Scan(&m.Version, &m.File, &m.Recorded)
+		if err != nil {
+			return err
+		}
+
+		// Compare with current script content
+		content, err := fs.ReadFile(l.migrateFs, path.Join(l.migrateDir, m.File))
+		if err == nil {
+			m.Current = scriptChecksum(content)
+		}
+
+		if m.Current != m.Recorded {
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	// Report by handler
+	if l.onMismatch != nil {
+		for _, m := range mismatches {
+			l.onMismatch(m)
+		}
+		return nil
+	}
+
+	return &ChecksumError{Mismatches: mismatches}
+}
+
+// Record checksum of all applied migration scripts, when checksum tracking is enabled.
+//
+// Existing records will not be overwritten, and records of versions
+// that no longer applied will be removed.
+func (l *LazyDB) recordChecksums(ctx context.Context) error {
+	if !l.checksums {
+		return nil
+	}
+
+	version, _, applied, err := readSchemaVersion(l.db)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := listMigrations(l.migrateFs, l.migrateDir)
+	if err != nil {
+		return err
+	}
+
+	queries := []ParamQuery{
+		Param("CREATE TABLE IF NOT EXISTS " + checksumTable + " (" +
+			"version INTEGER PRIMARY KEY, " +
+			"file TEXT NOT NULL, " +
+			"checksum TEXT NOT NULL, " +
+			"applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)"),
+	}
+
+	// Remove versions that no longer applied
+	if applied {
+		queries = append(queries, Param("DELETE FROM "+checksumTable+" WHERE version > ?", version))
+	} else {
+		queries = append(queries, Param("DELETE FROM "+checksumTable))
+	}
+
+	// Record applied versions
+	for _, m := range migrations {
+		if !applied || m.Version > version || m.Up == "" {
+			continue
+		}
+
+		content, err := fs.ReadFile(l.migrateFs, path.Join(l.migrateDir, m.Up))
+		if err != nil {
+			return err
+		}
+
+		queries = append(queries, Param(
+			"INSERT OR IGNORE INTO "+checksumTable+" (version, file, checksum) VALUES (?, ?, ?)",
+			m.Version, m.Up, scriptChecksum(content),
+		))
+	}
+
+	// Writer lock is already held by migration
+	_, err = l.execMultiple(ctx, queries)
+	return err
+}
@@ -0,0 +1,121 @@
+package lazydb
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Create migration fs for checksum testing.
+func checksumTestFs() fstest.MapFS {
+	return fstest.MapFS{
+		"schema/1_init.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER)")},
+		"schema/1_init.down.sql": {Data: []byte("DROP TABLE a")},
+		"schema/2_tbl.up.sql":    {Data: []byte("CREATE TABLE b (id INTEGER)")},
+		"schema/2_tbl.down.sql":  {Data: []byte("DROP TABLE b")},
+	}
+}
+
+// Count checksum records in database.
+func countChecksums(t *testing.T, l *LazyDB) int {
+	var ct int
+	err := l.DB().QueryRow("SELECT COUNT(*) FROM " + checksumTable).Scan(&ct)
+	if err != nil {
+		t.Fatal("Failed to count checksums: ", err)
+	}
+	return ct
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	migrationFs := checksumTestFs()
+
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "checksum.db")),
+		Migrate(migrationFs, "schema"),
+		VerifyChecksums(nil),
+	)
+	db.Connect()
+	defer db.Close()
+
+	// Checksums recorded after migration
+	_, err := db.MigrateTo(1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 1, countChecksums(t, db))
+
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 2, countChecksums(t, db))
+
+	// Checksums removed after migrate down
+	_, err = db.MigrateSteps(-1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 1, countChecksums(t, db))
+
+	// Modify applied script
+	migrationFs["schema/1_init.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id TEXT)")}
+
+	_, err = db.Migrate()
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	var sumErr *ChecksumError
+	if assert.ErrorAs(t, err, &sumErr) {
+		assert.Len(t, sumErr.Mismatches, 1)
+		assert.EqualValues(t, 1, sumErr.Mismatches[0].Version)
+		assert.EqualValues(t, "1_init.up.sql", sumErr.Mismatches[0].File)
+	}
+
+	// Migration must not be performed
+	version, _, _, _ := readSchemaVersion(db.DB())
+	assert.EqualValues(t, 1, version)
+}
+
+func TestVerifyChecksumsHandler(t *testing.T) {
+	migrationFs := checksumTestFs()
+
+	// Record mismatches by handler
+	mismatches := make([]ChecksumMismatch, 0)
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "checksum.db")),
+		Migrate(migrationFs, "schema"),
+		VerifyChecksums(func(m ChecksumMismatch) { mismatches = append(mismatches, m) }),
+	)
+	db.Connect()
+	defer db.Close()
+
+	_, err := db.MigrateTo(1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	// Remove applied script
+	delete(migrationFs, "schema/1_init.up.sql")
+
+	// Migration should be continued
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	if assert.Len(t, mismatches, 1) {
+		assert.EqualValues(t, 1, mismatches[0].Version)
+		assert.EqualValues(t, "1_init.up.sql", mismatches[0].File)
+		assert.EqualValues(t, "", mismatches[0].Current)
+	}
+
+	version, _, _, _ := readSchemaVersion(db.DB())
+	assert.EqualValues(t, 2, version)
+}
+
+// Ensure no checksum table created when checksum tracking is disabled.
+func TestNoChecksums(t *testing.T) {
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "checksum.db")),
+		Migrate(checksumTestFs(), "schema"),
+	)
+	db.Connect()
+	defer db.Close()
+
+	_, err := db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	exist, err := tableExists(db.DB(), checksumTable)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.False(t, exist)
+}
@@ -15,6 +15,9 @@ var Dir = "schema"
 // Default File path of database.
 var Path = "data.db"
 
+// Default maximum number of cached prepared statements.
+var StmtCache = 32
+
 // Get default options when creating database.
 func defaultOpts() databaseOpts {
 	return databaseOpts{
@@ -22,5 +25,6 @@ func defaultOpts() databaseOpts {
 		MigrateFS:     embed.FS{},
 		MigrateDir:    Dir,
 		SchemaVersion: Latest,
+		StmtCacheSize: StmtCache,
 	}
 }
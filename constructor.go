@@ -1,7 +1,11 @@
 package lazydb
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"io/fs"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -20,11 +24,44 @@ type LazyDB struct {
 
 	connected bool // Determine database is connected
 
-	dbPath        string // Database absolute path, for easy reuse
-	migrateFs     fs.FS  // FS for schema migrations sql scripts
-	migrateDir    string // Directory for storing migration script, default is "schema"
-	schemaVersion uint   // version of migration script to use
-	backupDir     string // directory to backup, or empty string for no backup. Default is empty string.
+	dbPath        string          // Database absolute path, for easy reuse
+	migrateFs     fs.FS           // FS for schema migrations sql scripts
+	migrateDir    string          // Directory for storing migration script, default is "schema"
+	schemaVersion uint            // version of migration script to use
+	backupDir     string          // directory to backup, or empty string for no backup. Default is empty string.
+	retention     RetentionPolicy // policy to remove old backups, default is keep all backups
+	rollback      bool            // restore backup when migration failed, default is false
+
+	strictMigrations bool                   // validate migration directory before migration, default is false
+	checksums        bool                   // record & verify checksum of applied migration, default is false
+	onMismatch       func(ChecksumMismatch) // handler of checksum mismatch, nil for return error
+
+	goMigrations map[uint]goMigration // migrations defined by Go functions, by version
+	hooks        MigrationHooks       // callbacks invoked around migration
+
+	backupProgress func(remaining, total int) // callback to report backup progress, can be nil
+
+	stmtCacheSize int        // maximum number of cached prepared statements, non-positive for no caching
+	stmts         *stmtCache // cache of prepared statements, created when connected
+
+	retry  RetryPolicy  // policy to retry execution when database is busy, default is no retry
+	tracer *queryTracer // tracer to receive every executed statement, nil for no tracing
+
+	pragmas []pragma      // pragmas applied to every connection, verified after connected
+	pool    PoolConfig    // configuration of connection pool, default is no limit
+	writer  chan struct{} // lock of single writer, created when connected
+
+	readers   int        // number of reader connections in split-pool mode, 0 for single pool
+	reader    *sql.DB    // read-only pool in split-pool mode, nil for single pool
+	readStmts *stmtCache // cache of prepared statements of reader pool
+
+	storage   storageMode // storage of database, default is file in dbPath
+	memoryDsn string      // DSN of in-memory database, empty if not in-memory
+	keepalive driver.Conn // connection that keep in-memory database alive
+	tempDir   string      // temporary directory of database, removed when closed
+
+	readOnly  bool // open database file in read-only mode, default is false
+	immutable bool // treat read-only database file as immutable, default is false
 }
 
 // Create a new LazyDB.
@@ -44,34 +81,85 @@ func New(opts ...DatabaseOption) *LazyDB {
 		migrateFs:     opt.MigrateFS,
 		schemaVersion: opt.SchemaVersion,
 		backupDir:     opt.BackupDir,
+		retention:     opt.Retention,
+		rollback:      opt.Rollback,
+
+		strictMigrations: opt.Strict,
+		checksums:        opt.Checksums,
+		onMismatch:       opt.OnMismatch,
+
+		goMigrations: opt.GoMigrations,
+		hooks:        opt.Hooks,
+
+		backupProgress: opt.BackupProgress,
+
+		stmtCacheSize: opt.StmtCacheSize,
+
+		retry:  opt.Retry,
+		tracer: newQueryTracer(opt.Tracer, opt.Redact),
+
+		pragmas: opt.Pragmas,
+		pool:    opt.Pool,
+
+		readers: opt.Readers,
+		storage: opt.Storage,
+
+		readOnly:  opt.ReadOnly,
+		immutable: opt.Immutable,
 	}
 }
 
 // Connect to database, when path already stored in LazyDB.
 func (l *LazyDB) Connect() error {
+	return l.ConnectContext(context.Background())
+}
+
+// Connect to database, when path already stored in LazyDB.
+//
+// The context is used to cancel the connection test.
+func (l *LazyDB) ConnectContext(ctx context.Context) error {
 	// Prevent Empty Path
 	if l.dbPath == "" {
 		return ErrEmptyPath
 	}
 
-	var err error
+	// Prevent read-only mode of database that newly created
+	if l.readOnly && l.storage != storageFile {
+		return fmt.Errorf("%w: cannot be used with in-memory or temporary database", ErrReadOnly)
+	}
 
-	// Create Database if need
-	err = createDbFile(l.dbPath)
+	// Create Database or in-memory storage if need
+	dsn, created, err := l.prepareStorage(ctx)
 	if err != nil {
+		if created {
+			err = errors.Join(err, l.releaseStorage())
+		}
 		return err
 	}
 
-	// Open database connection, which create file if not exist
-	l.db, err = sql.Open(DatabaseType, l.dbPath)
+	// Open database connection with pragmas, which create file if not exist
+	if l.splitPools() {
+		l.db, l.reader, err = l.openSplitPools(ctx, dsn)
+	} else {
+		l.db, err = openPool(ctx, dsn, l.pragmas, l.pool)
+	}
 	if err != nil {
+		// Storage kept by previous connection is not released, e.g. reconnect after restore
+		if created {
+			err = errors.Join(err, l.releaseStorage())
+		}
 		return err
 	}
 
-	// Test DB connection by ping
-	err = l.db.Ping()
-	if err != nil {
-		return err
+	// Writer lock is kept when reconnect, as writer may still waiting for it
+	if l.writer == nil {
+		l.writer = make(chan struct{}, 1)
+	}
+
+	// Prepared statements are bound to connection pool
+	l.stmts = newStmtCache(l.stmtCacheSize)
+	if l.reader != nil {
+		l.readStmts = newStmtCache(l.stmtCacheSize)
 	}
 
 	// Database successfully connected
@@ -80,24 +168,48 @@ func (l *LazyDB) Connect() error {
 }
 
 // Close all existing database connection.
+// In-memory database and temporary database file are also removed.
 //
-// If LazyDB has no database connected, then this function has no effect,
-// with no error returned.
+// If LazyDB has no database connected, then only in-memory database
+// or temporary database file (if any) is removed, with no error returned if success.
 func (l *LazyDB) Close() error {
+	err := l.closePools()
+	return errors.Join(err, l.releaseStorage())
+}
+
+// Close connection pools & cached statements, with storage of database kept.
+func (l *LazyDB) closePools() error {
 	// Prevent no connection for nil pointer
 	if l.db == nil {
 		return nil
 	}
 
+	// Close cached statements before connection
+	var errs []error
+	if l.stmts != nil {
+		errs = append(errs, l.stmts.close())
+		l.stmts = nil
+	}
+	if l.readStmts != nil {
+		errs = append(errs, l.readStmts.close())
+		l.readStmts = nil
+	}
+
+	// Close reader pool in split-pool mode
+	if l.reader != nil {
+		errs = append(errs, l.reader.Close())
+		l.reader = nil
+	}
+
 	// Close connection
-	err := l.db.Close()
+	errs = append(errs, l.db.Close())
 	l.db = nil
 
 	// Return error
-	return err
+	return errors.Join(errs...)
 }
 
-// Get *sql.DB created.
+// Get *sql.DB created. In split-pool mode, this is the writer pool.
 func (l *LazyDB) DB() *sql.DB {
 	return l.db
 }
@@ -107,3 +219,12 @@ func (l *LazyDB) DB() *sql.DB {
 func (l *LazyDB) Connected() bool {
 	return l.connected
 }
+
+// Get statistics of connection pool. In split-pool mode, this is the writer pool.
+// Zero value will be returned if database is not connected.
+func (l *LazyDB) PoolStats() sql.DBStats {
+	if l.db == nil {
+		return sql.DBStats{}
+	}
+	return l.db.Stats()
+}
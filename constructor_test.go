@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
@@ -23,6 +24,7 @@ func TestNew(t *testing.T) {
 		migrateFs:     embed.FS{},
 		schemaVersion: 0,
 		backupDir:     "",
+		stmtCacheSize: 32,
 	}, db1, "Incorrect value in default.")
 
 	// Case 2~3: Partly Modify
@@ -33,6 +35,7 @@ func TestNew(t *testing.T) {
 		migrateFs:     embed.FS{},
 		schemaVersion: 2,
 		backupDir:     "",
+		stmtCacheSize: 32,
 	}, db2, "Incorrect value in modified 1.")
 
 	db3 := New(Migrate(embed.FS{}, "kk"))
@@ -42,6 +45,7 @@ func TestNew(t *testing.T) {
 		migrateFs:     embed.FS{},
 		schemaVersion: 0,
 		backupDir:     "",
+		stmtCacheSize: 32,
 	}, db3, "Incorrect value in modified 2.")
 
 	db4 := New(BackupDir("./abc"))
@@ -51,6 +55,7 @@ func TestNew(t *testing.T) {
 		migrateFs:     embed.FS{},
 		schemaVersion: 0,
 		backupDir:     "./abc",
+		stmtCacheSize: 32,
 	}, db4, "Incorrect value in modified 2.")
 
 	// Case 4: All modify
@@ -61,6 +66,7 @@ func TestNew(t *testing.T) {
 		migrateFs:     embed.FS{},
 		schemaVersion: 14,
 		backupDir:     "./abc",
+		stmtCacheSize: 32,
 	}, db5, "Incorrect value in all modified.")
 }
 
@@ -151,3 +157,36 @@ func TestClose(t *testing.T) {
 		assert.Nilf(t, l.db, "Case %d: Unexpected non-nil database connection.", idx)
 	}
 }
+
+// Test connection pool configuration & statistics.
+func TestPoolStats(t *testing.T) {
+	type testCase struct {
+		opts     []DatabaseOption
+		wantOpen int
+	}
+
+	tests := []testCase{
+		// Default of database/sql, no limit
+		{nil, 0},
+		{[]DatabaseOption{Pool(PoolConfig{MaxOpenConns: 1})}, 1},
+		{[]DatabaseOption{Pool(PoolConfig{MaxOpenConns: 3, MaxIdleConns: 3, ConnMaxLifetime: time.Minute})}, 3},
+	}
+
+	for idx, tt := range tests {
+		opts := append([]DatabaseOption{DbPath(filepath.Join(t.TempDir(), "pool.db"))}, tt.opts...)
+		db := New(opts...)
+
+		assert.EqualValuesf(t, sql.DBStats{}, db.PoolStats(), "Case %d: Stats should be empty before connected", idx)
+
+		err := db.Connect()
+		if err != nil {
+			t.Fatal("Failed to connect: ", err)
+		}
+
+		stats := db.PoolStats()
+		assert.EqualValuesf(t, tt.wantOpen, stats.MaxOpenConnections, "Case %d: Unexpected max open connections", idx)
+		assert.EqualValuesf(t, 1, stats.OpenConnections, "Case %d: Unexpected open connections", idx)
+
+		db.Close()
+	}
+}
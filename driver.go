@@ -0,0 +1,186 @@
+package lazydb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Database driver for migration, which wrap the sqlite3 driver to:
+//   - Execute Go migrations
+//   - Invoke hooks before & after each version
+type migrationDriver struct {
+	database.Driver // Driver to execute SQL scripts
+
+	ctx          context.Context      // Context of migration, stop migration when cancelled
+	db           *sql.DB              // Database to run Go migrations
+	goMigrations map[uint]goMigration // Go migrations by version
+	hooks        MigrationHooks       // Hooks to invoke around each version
+	backupPath   string               // Backup created before migration, passed to hooks
+	tracer       *queryTracer         // Tracer to receive each migration script, can be nil
+
+	current int // Version before running migration, -1 for nil version
+	pending int // Version after running migration, -1 for nil version
+}
+
+// Create migration driver that wrap given database driver.
+func (l *LazyDB) migrationDriver(inner database.Driver) (*migrationDriver, error) {
+	current, _, err := inner.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrationDriver{
+		Driver:       inner,
+		ctx:          context.Background(),
+		db:           l.db,
+		goMigrations: l.goMigrations,
+		hooks:        l.hooks,
+		tracer:       l.tracer,
+		current:      current,
+		pending:      current,
+	}, nil
+}
+
+func (d *migrationDriver) SetVersion(version int, dirty bool) error {
+	// Stop before next version is started, even graceful stop is not yet received by golang-migrate
+	if dirty && d.ctx.Err() != nil {
+		return d.ctx.Err()
+	}
+
+	err := d.Driver.SetVersion(version, dirty)
+	if err != nil {
+		return err
+	}
+
+	// Dirty version is set before running migration, and clean version after
+	if dirty {
+		d.pending = version
+	} else {
+		d.current = version
+	}
+
+	return nil
+}
+
+func (d *migrationDriver) Run(migration io.Reader) error {
+	body, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+
+	event := d.event()
+
+	err = callHook(d.hooks.BeforeVersion, event)
+	if err != nil {
+		// Script is not executed, revert dirty version that marked before running
+		return errors.Join(err, d.revertVersion())
+	}
+
+	start := time.Now()
+	err = d.run(body)
+	d.tracer.trace(d.ctx, QueryOpMigration, string(body), nil, start, nil, err)
+
+	// Script is interrupted & rolled back by cancelled context, revert dirty version
+	if err != nil && d.ctx.Err() != nil {
+		return errors.Join(d.ctx.Err(), d.revertVersion())
+	}
+	if err != nil {
+		return err
+	}
+
+	return callHook(d.hooks.AfterVersion, event)
+}
+
+// Set version back to clean version before running migration.
+func (d *migrationDriver) revertVersion() error {
+	err := d.Driver.SetVersion(d.current, false)
+	if err != nil {
+		return err
+	}
+
+	d.pending = d.current
+	return nil
+}
+
+// Get event of migration that going to run.
+func (d *migrationDriver) event() MigrationEvent {
+	event := MigrationEvent{
+		DB:         d.db,
+		From:       versionOf(d.current),
+		To:         versionOf(d.pending),
+		BackupPath: d.backupPath,
+	}
+
+	// Determine which version of script is running
+	if d.pending > d.current {
+		event.Version, event.Direction = event.To, DirectionUp
+	} else {
+		event.Version, event.Direction = event.From, DirectionDown
+	}
+
+	return event
+}
+
+// Run migration body, which can be SQL script or Go migration.
+func (d *migrationDriver) run(body []byte) error {
+	// Not a Go migration
+	if !bytes.HasPrefix(body, []byte(goMigrationPrefix)) {
+		return runScript(d.ctx, d.db, body)
+	}
+
+	// Parse version & direction
+	var version uint
+	var direction source.Direction
+	_, err := fmt.Sscanf(string(body[len(goMigrationPrefix):]), "%d %s", &version, &direction)
+	if err != nil {
+		return fmt.Errorf("invalid Go migration body %q: %w", body, err)
+	}
+
+	fn := d.goMigrations[version].Up
+	if direction == source.Down {
+		fn = d.goMigrations[version].Down
+	}
+
+	if fn == nil {
+		return fmt.Errorf("%w: Go migration of version %d has no %s function", ErrInvalidMigration, version, direction)
+	}
+
+	return runGoMigration(d.ctx, d.db, fn)
+}
+
+// Run SQL script inside transaction, same as sqlite3 database driver,
+// but interrupted when context is cancelled.
+func runScript(ctx context.Context, db *sql.DB, body []byte) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction start failed"}
+	}
+	defer tx.Rollback() // Any failed tx will cause rollback
+
+	_, err = tx.ExecContext(ctx, string(body))
+	if err != nil {
+		return &database.Error{OrigErr: err, Query: body}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction commit failed"}
+	}
+	return nil
+}
+
+// Convert version of database driver to uint, which nil version will be 0.
+func versionOf(version int) uint {
+	if version < 0 {
+		return 0
+	}
+	return uint(version)
+}
@@ -1,6 +1,9 @@
 package lazydb
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Error when user pass empty string as database path parameter.
 var ErrEmptyPath = errors.New("empty database file path")
@@ -19,3 +22,51 @@ var ErrEmptyDir = errors.New("empty string for migration directory")
 
 // Error when migration directory structure is not correct.
 var ErrInvalidDir = errors.New("invalid migration directory structure")
+
+// Error when migration scripts failed the validation.
+var ErrInvalidMigration = errors.New("invalid migration scripts")
+
+// Error when applied migration script is modified.
+var ErrChecksumMismatch = errors.New("checksum mismatch of applied migration")
+
+// Error when target version is not exist in migration fs.
+var ErrVersionNotFound = errors.New("version not found in migration directory")
+
+// Error when the file to restore is not a valid SQLite database.
+var ErrInvalidBackup = errors.New("invalid backup database")
+
+// Error when schema version of backup is not compatible with migration schema.
+var ErrIncompatibleSchema = errors.New("incompatible schema version")
+
+// Error when query result has column that cannot be mapped to struct field.
+var ErrUnmappedColumn = errors.New("column has no matching struct field")
+
+// Error when argument type cannot be formatted as SQL literal.
+var ErrUnsupportedType = errors.New("unsupported argument type")
+
+// Error when pragma option has invalid name or value.
+var ErrInvalidPragma = errors.New("invalid pragma")
+
+// Error when pragma value queried after connected is different from option.
+var ErrPragmaNotApplied = errors.New("pragma not applied")
+
+// Error when try to modify database that opened in read-only mode.
+var ErrReadOnly = errors.New("database is opened in read-only mode")
+
+// Error when migration failed during execution of migration scripts.
+type MigrationError struct {
+	Version      uint   // Version that migration failed at, or target version if unknown
+	Err          error  // Original error returned by migration
+	RestoredPath string // Path of backup that restored after failure, empty if no restore performed
+}
+
+func (e *MigrationError) Error() string {
+	if e.RestoredPath != "" {
+		return fmt.Sprintf("migration failed at version %d, restored from %s: %v", e.Version, e.RestoredPath, e.Err)
+	}
+	return fmt.Sprintf("migration failed at version %d: %v", e.Version, e.Err)
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,173 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Function of Go migration, which executed inside a transaction.
+// Returning non-nil error will rollback the transaction and fail the migration.
+type GoMigrationFunc func(tx *sql.Tx) error
+
+// Go functions to migrate single version.
+type goMigration struct {
+	Up   GoMigrationFunc // Function to upgrade schema, must not be nil
+	Down GoMigrationFunc // Function to downgrade schema, nil for only changing version when downgrade
+}
+
+// Prefix of migration body that represent a Go migration.
+// Format of full body is "{prefix}{version} {direction}".
+const goMigrationPrefix = "-- lazydb:go "
+
+// Identifier of Go migration, which used as migration name.
+const goMigrationName = "go"
+
+// Get all migrations of LazyDB, include SQL scripts in migration fs & Go migrations,
+// sorted by version in ascending order.
+func (l *LazyDB) migrations() ([]Migration, error) {
+	migrations, err := listMigrations(l.migrateFs, l.migrateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(l.goMigrations) == 0 {
+		return migrations, nil
+	}
+
+	// Ensure no version conflict
+	for _, m := range migrations {
+		if _, ok := l.goMigrations[m.Version]; ok {
+			return nil, fmt.Errorf("%w: version %d is defined by both script and Go migration", ErrInvalidMigration, m.Version)
+		}
+	}
+
+	for version, fn := range l.goMigrations {
+		if version == 0 {
+			return nil, fmt.Errorf("%w: version of Go migration must be positive", ErrInvalidMigration)
+		}
+		if fn.Up == nil {
+			return nil, fmt.Errorf("%w: Go migration of version %d has no up function", ErrInvalidMigration, version)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: goMigrationName, Go: true})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Get largest version of LazyDB, include SQL scripts in migration fs & Go migrations.
+func (l *LazyDB) largestVersion() (uint, error) {
+	latest, err := LargestSchemaVer(l.migrateFs, l.migrateDir)
+	if err != nil {
+		return 0, err
+	}
+
+	for version := range l.goMigrations {
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
+
+// Source driver that merge Go migrations into SQL scripts source.
+//
+// Go migration is represented as a special body, which will be executed by migrationDriver.
+type goSource struct {
+	source.Driver // Source of SQL scripts
+
+	versions []uint               // All versions in ascending order
+	funcs    map[uint]goMigration // Go migrations by version
+}
+
+// Create source driver that contains both SQL scripts & Go migrations of LazyDB.
+func (l *LazyDB) goSource(inner source.Driver) (*goSource, error) {
+	migrations, err := l.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]uint, 0, len(migrations))
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+
+	return &goSource{Driver: inner, versions: versions, funcs: l.goMigrations}, nil
+}
+
+func (s *goSource) First() (version uint, err error) {
+	if len(s.versions) == 0 {
+		return 0, &fs.PathError{Op: "first", Path: goMigrationName, Err: fs.ErrNotExist}
+	}
+	return s.versions[0], nil
+}
+
+func (s *goSource) Prev(version uint) (prevVersion uint, err error) {
+	idx := sort.Search(len(s.versions), func(i int) bool { return s.versions[i] >= version })
+	if idx == 0 || idx == len(s.versions) || s.versions[idx] != version {
+		return 0, &fs.PathError{Op: fmt.Sprintf("prev for version %d", version), Path: goMigrationName, Err: fs.ErrNotExist}
+	}
+	return s.versions[idx-1], nil
+}
+
+func (s *goSource) Next(version uint) (nextVersion uint, err error) {
+	idx := sort.Search(len(s.versions), func(i int) bool { return s.versions[i] > version })
+	if idx == len(s.versions) {
+		return 0, &fs.PathError{Op: fmt.Sprintf("next for version %d", version), Path: goMigrationName, Err: fs.ErrNotExist}
+	}
+	return s.versions[idx], nil
+}
+
+func (s *goSource) ReadUp(version uint) (r io.ReadCloser, identifier string, err error) {
+	fn, ok := s.funcs[version]
+	if !ok {
+		return s.Driver.ReadUp(version)
+	}
+	return goMigrationBody(version, source.Up, fn.Up)
+}
+
+func (s *goSource) ReadDown(version uint) (r io.ReadCloser, identifier string, err error) {
+	fn, ok := s.funcs[version]
+	if !ok {
+		return s.Driver.ReadDown(version)
+	}
+	return goMigrationBody(version, source.Down, fn.Down)
+}
+
+// Get body that represent a Go migration. Nil function will be considered as not exist.
+func goMigrationBody(version uint, direction source.Direction, fn GoMigrationFunc) (io.ReadCloser, string, error) {
+	if fn == nil {
+		return nil, "", &fs.PathError{Op: fmt.Sprintf("read %s for version %d", direction, version), Path: goMigrationName, Err: fs.ErrNotExist}
+	}
+
+	body := fmt.Sprintf("%s%d %s", goMigrationPrefix, version, direction)
+	return io.NopCloser(strings.NewReader(body)), goMigrationName, nil
+}
+
+// Run Go migration function inside transaction.
+func runGoMigration(ctx context.Context, db *sql.DB, fn GoMigrationFunc) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // Any failed tx will cause rollback
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,165 @@
+package lazydb
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Migration fs with version 2 missing, which expected to be filled by Go migration.
+var goTestFs = fstest.MapFS{
+	"schema/1_init.up.sql":   {Data: []byte("CREATE TABLE users (name TEXT)")},
+	"schema/1_init.down.sql": {Data: []byte("DROP TABLE users")},
+	"schema/3_tbl.up.sql":    {Data: []byte("CREATE TABLE others (id INTEGER)")},
+	"schema/3_tbl.down.sql":  {Data: []byte("DROP TABLE others")},
+}
+
+// Go migration that insert a user.
+func goTestUp(tx *sql.Tx) error {
+	_, err := tx.Exec("INSERT INTO users (name) VALUES ('abc')")
+	return err
+}
+
+// Go migration that remove all users.
+func goTestDown(tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM users")
+	return err
+}
+
+// Count users inserted by Go migration.
+func countUsers(t *testing.T, l *LazyDB) int {
+	var ct int
+	err := l.DB().QueryRow("SELECT COUNT(*) FROM users").Scan(&ct)
+	if err != nil {
+		t.Fatal("Failed to count users: ", err)
+	}
+	return ct
+}
+
+func TestGoMigration(t *testing.T) {
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "go.db")),
+		Migrate(goTestFs, "schema"),
+		GoMigration(2, goTestUp, goTestDown),
+		StrictMigrations(),
+	)
+	db.Connect()
+	defer db.Close()
+
+	// Plan should contains Go migration
+	scripts, err := db.PlanMigration(0)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []PlannedScript{
+		{1, "init", DirectionUp, "1_init.up.sql", "CREATE TABLE users (name TEXT)", false},
+		{2, "go", DirectionUp, "", "", true},
+		{3, "tbl", DirectionUp, "3_tbl.up.sql", "CREATE TABLE others (id INTEGER)", false},
+	}, scripts)
+
+	// Migrate up to latest
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 1, countUsers(t, db))
+
+	status, err := db.MigrationStatus()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 3, status.Version)
+	assert.EqualValues(t, []uint{1, 2, 3}, migrationVersions(status.Applied))
+
+	// Migrate down to version 1, which Go migration is reverted
+	_, err = db.MigrateSteps(-2)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 0, countUsers(t, db))
+
+	version, _, _, _ := readSchemaVersion(db.DB())
+	assert.EqualValues(t, 1, version)
+}
+
+func TestGoMigrationFailed(t *testing.T) {
+	errTest := errors.New("test error")
+
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "go.db")),
+		Migrate(goTestFs, "schema"),
+		GoMigration(2, func(tx *sql.Tx) error {
+			// Changes should be rollback
+			goTestUp(tx)
+			return errTest
+		}, nil),
+	)
+	db.Connect()
+	defer db.Close()
+
+	_, err := db.Migrate()
+	assert.ErrorIs(t, err, errTest)
+
+	var migErr *MigrationError
+	if assert.ErrorAs(t, err, &migErr) {
+		assert.EqualValues(t, 2, migErr.Version)
+	}
+
+	assert.EqualValues(t, 0, countUsers(t, db))
+}
+
+// Ensure Go migration without down function is skipped when migrate down,
+// same as script without down file.
+func TestGoMigrationNoDown(t *testing.T) {
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "go.db")),
+		Migrate(goTestFs, "schema"),
+		GoMigration(2, goTestUp, nil),
+	)
+	db.Connect()
+	defer db.Close()
+
+	_, err := db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	// Down migration of version 2 is not planned
+	scripts, err := db.PlanMigration(1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	if assert.Len(t, scripts, 1) {
+		assert.EqualValues(t, 3, scripts[0].Version)
+	}
+
+	// Version is changed, but data inserted by up function is kept
+	_, err = db.MigrateTo(1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 1, countUsers(t, db))
+
+	version, dirty, _, _ := readSchemaVersion(db.DB())
+	assert.EqualValues(t, 1, version)
+	assert.False(t, dirty)
+}
+
+func TestGoMigrationInvalid(t *testing.T) {
+	type testCase struct {
+		version uint
+		up      GoMigrationFunc
+	}
+
+	tests := []testCase{
+		// Version conflict with script
+		{1, goTestUp},
+		// Zero version
+		{0, goTestUp},
+		// No up function
+		{2, nil},
+	}
+
+	for idx, tt := range tests {
+		db := New(
+			DbPath(filepath.Join(t.TempDir(), "go.db")),
+			Migrate(goTestFs, "schema"),
+			GoMigration(tt.version, tt.up, nil),
+		)
+		db.Connect()
+		defer db.Close()
+
+		_, err := db.Migrate()
+		assert.ErrorIsf(t, err, ErrInvalidMigration, "Case %d: Unexpected error: %v", idx, err)
+	}
+}
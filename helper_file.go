@@ -2,10 +2,12 @@ package lazydb
 
 import (
 	"fmt"
-	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+
+	"github.com/golang-migrate/migrate/v4/source"
 )
 
 // Create a new database file, WITHOUT apply any schema changes.
@@ -98,29 +100,63 @@ func LargestSchemaVer(fileSys fs.FS, folder string) (uint, error) {
 	return uint(maxVersion), nil
 }
 
-// Copy file from source to destination.
-func copyFile(src, dst string) error {
-	stat, err := os.Stat(src)
+// Migration scripts of single version inside migration fs.
+type Migration struct {
+	Version uint   // Version number, parsed from filename prefix
+	Name    string // Identifier of migration, e.g. "init" for 1_init.up.sql
+	Up      string // Filename of up script, empty if not exist
+	Down    string // Filename of down script, empty if not exist
+	Go      bool   // Migration is defined by Go functions instead of scripts
+}
+
+// Get all migrations in given fs.FS, sorted by version in ascending order.
+//
+// Files that not follow migration naming will be skipped.
+// If more than one script exist for same version & direction, only the first one will be used.
+func listMigrations(fileSys fs.FS, folder string) ([]Migration, error) {
+	// Get Directory List 1st
+	entries, err := fs.ReadDir(fileSys, folder)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if !stat.Mode().IsRegular() {
-		return fmt.Errorf("%s is not a regular file", src)
-	}
+	migrations := make(map[uint]*Migration)
 
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
+	for _, entry := range entries {
+		// Ensure No nested directories inside
+		if entry.IsDir() {
+			return nil, ErrInvalidDir
+		}
+
+		// Skip not related file
+		m, err := source.DefaultParse(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		item, ok := migrations[m.Version]
+		if !ok {
+			item = &Migration{Version: m.Version, Name: m.Identifier}
+			migrations[m.Version] = item
+		}
+
+		// Record filename by direction
+		if m.Direction == source.Up && item.Up == "" {
+			item.Up = entry.Name()
+		} else if m.Direction == source.Down && item.Down == "" {
+			item.Down = entry.Name()
+		}
 	}
-	defer srcFile.Close()
 
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
+	// Sort by version
+	result := make([]Migration, 0, len(migrations))
+	for _, item := range migrations {
+		result = append(result, *item)
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, srcFile)
-	return err
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	return result, nil
 }
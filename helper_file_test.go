@@ -110,3 +110,38 @@ func TestLargestSchemaVer(t *testing.T) {
 		assert.EqualValuesf(t, tt.want, got, "Case %d: unexpected version number.", idx)
 	}
 }
+
+func TestListMigrations(t *testing.T) {
+	type testCase struct {
+		fs      embed.FS
+		folder  string
+		want    []Migration
+		wantErr bool
+	}
+
+	tests := []testCase{
+		{fsOtherTest, "test_schema/with_others", []Migration{
+			{Version: 1, Name: "init", Up: "1_init.up.sql", Down: "1_init.down.sql"},
+			{Version: 2, Name: "tbl", Up: "2_tbl.up.sql", Down: "2_tbl.down.sql"},
+		}, false},
+		{fsSkippedTest, "test_schema/skipped", []Migration{
+			{Version: 1, Name: "init", Up: "1_init.up.sql", Down: "1_init.down.sql"},
+			{Version: 3, Name: "data", Up: "3_data.up.sql", Down: "3_data.down.sql"},
+		}, false},
+
+		{fsNormalTestV3, "abc", nil, true},
+		{embed.FS{}, "test_schema/normal_v3", nil, true},
+	}
+
+	for idx, tt := range tests {
+		got, err := listMigrations(tt.fs, tt.folder)
+
+		if tt.wantErr {
+			assert.NotNilf(t, err, "Case %d: err should be non-nil", idx)
+			continue
+		}
+
+		assert.Nilf(t, err, "Case %d: err should be nil, but %v", idx, err)
+		assert.EqualValuesf(t, tt.want, got, "Case %d: unexpected migrations.", idx)
+	}
+}
@@ -0,0 +1,39 @@
+package lazydb
+
+import "database/sql"
+
+// Information of migration that passed to hooks.
+type MigrationEvent struct {
+	DB         *sql.DB // Database that being migrated
+	From       uint    // Version before migration, 0 if no version
+	To         uint    // Version after migration, 0 if no version
+	BackupPath string  // Backup created before migration, empty if no backup
+
+	// Fields below are only available in BeforeVersion & AfterVersion
+
+	Version   uint      // Version of script that running, i.e. To for up and From for down
+	Direction Direction // Direction of script that running
+}
+
+// Callbacks that invoked around migration. Any nil callback will be skipped.
+//
+// Returning non-nil error from callback will stop the migration, and the error will be
+// returned as *MigrationError. Error from AfterAll is returned directly,
+// as all scripts are already executed.
+//
+// Hooks are only invoked when migration actually changes database version.
+// Writes by LazyDB wait until migration finished, so hooks should use DB of MigrationEvent to write.
+type MigrationHooks struct {
+	BeforeAll     func(MigrationEvent) error // Invoked before first script is executed
+	AfterAll      func(MigrationEvent) error // Invoked after all scripts are executed successfully
+	BeforeVersion func(MigrationEvent) error // Invoked before script of each version is executed
+	AfterVersion  func(MigrationEvent) error // Invoked after script of each version is executed successfully
+}
+
+// Invoke given hook if it is not nil.
+func callHook(hook func(MigrationEvent) error, event MigrationEvent) error {
+	if hook == nil {
+		return nil
+	}
+	return hook(event)
+}
@@ -0,0 +1,138 @@
+package lazydb
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Create hooks that record all events as string.
+func recordHooks(records *[]string) MigrationHooks {
+	record := func(name string) func(MigrationEvent) error {
+		return func(e MigrationEvent) error {
+			if e.DB == nil {
+				return fmt.Errorf("nil database in event")
+			}
+
+			str := fmt.Sprintf("%s %d->%d", name, e.From, e.To)
+			if e.Version > 0 {
+				str += fmt.Sprintf(" %d %s", e.Version, e.Direction)
+			}
+			if e.BackupPath != "" {
+				str += " bk"
+			}
+
+			*records = append(*records, str)
+			return nil
+		}
+	}
+
+	return MigrationHooks{
+		BeforeAll:     record("before_all"),
+		AfterAll:      record("after_all"),
+		BeforeVersion: record("before"),
+		AfterVersion:  record("after"),
+	}
+}
+
+func TestMigrationHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	records := make([]string, 0)
+
+	db := New(
+		DbPath(filepath.Join(tmpDir, "hooks.db")),
+		Migrate(fsNormalTestV3, dirNormalTestV3),
+		BackupDir(filepath.Join(tmpDir, "bk")),
+		Hooks(recordHooks(&records)),
+	)
+	db.Connect()
+	defer db.Close()
+
+	// New database, no backup
+	_, err := db.MigrateTo(1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []string{
+		"before_all 0->1",
+		"before 0->1 1 up",
+		"after 0->1 1 up",
+		"after_all 0->1",
+	}, records)
+
+	// Upgrade with backup
+	records = records[:0]
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []string{
+		"before_all 1->3 bk",
+		"before 1->2 2 up bk",
+		"after 1->2 2 up bk",
+		"before 2->3 3 up bk",
+		"after 2->3 3 up bk",
+		"after_all 1->3 bk",
+	}, records)
+
+	// No changes, no hooks
+	records = records[:0]
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []string{}, records)
+
+	// Downgrade
+	_, err = db.MigrateSteps(-1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []string{
+		"before_all 3->2 bk",
+		"before 3->2 3 down bk",
+		"after 3->2 3 down bk",
+		"after_all 3->2 bk",
+	}, records)
+}
+
+func TestMigrationHooksError(t *testing.T) {
+	errTest := errors.New("test error")
+
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "hooks.db")),
+		Migrate(fsNormalTestV3, dirNormalTestV3),
+		Hooks(MigrationHooks{
+			BeforeVersion: func(e MigrationEvent) error {
+				if e.Version == 3 {
+					return errTest
+				}
+				return nil
+			},
+		}),
+	)
+	db.Connect()
+	defer db.Close()
+
+	_, err := db.Migrate()
+	assert.ErrorIs(t, err, errTest)
+
+	var migErr *MigrationError
+	if assert.ErrorAs(t, err, &migErr) {
+		assert.EqualValues(t, 3, migErr.Version)
+	}
+
+	// Script of version 3 must not be executed
+	ver, _ := getUserVersion(db.DB())
+	assert.EqualValues(t, 2, ver)
+
+	// Database is left in clean version before vetoed version
+	status, err := db.MigrationStatus()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 2, status.Version)
+	assert.False(t, status.Dirty)
+	assert.EqualValues(t, []uint{1, 2}, migrationVersions(status.Applied))
+
+	// Retry without hook should be successful
+	db.hooks = MigrationHooks{}
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	ver, _ = getUserVersion(db.DB())
+	assert.EqualValues(t, 3, ver)
+}
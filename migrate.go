@@ -1,40 +1,71 @@
 package lazydb
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/golang-migrate/migrate/v4"
 	sqlite "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
 // Create a new `migrate.Migrate` instance, which can be used to migrate up/down.
+// The wrapped database driver is also returned, for passing information to hooks.
 //
 // Purpose of this function is to reuse code to create a new migrate object.
-func (l *LazyDB) migrateInstance() (*migrate.Migrate, error) {
+func (l *LazyDB) migrateInstance() (*migrate.Migrate, *migrationDriver, error) {
 	// Prevent db is nil
 	if l.db == nil {
-		return nil, ErrNilDatabase
+		return nil, nil, ErrNilDatabase
+	}
+
+	// Prevent modifying read-only database
+	if l.readOnly {
+		return nil, nil, ErrReadOnly
 	}
 
 	// Prevent empty migration directory
 	if l.migrateDir == "" {
-		return nil, ErrEmptyDir
+		return nil, nil, ErrEmptyDir
+	}
+
+	// Ensure migration directory is valid
+	err := l.validateMigrations()
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Get sqlite3 instance
 	instance, err := sqlite.WithInstance(l.db, &sqlite.Config{})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	driver, err := l.migrationDriver(instance)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Create iofs with embedded filesystem, with directory specified
-	f, err := iofs.New(l.migrateFs, l.migrateDir)
+	var src source.Driver
+	src, err = iofs.New(l.migrateFs, l.migrateDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return migrate.NewWithInstance("iofs", f, "sqlite3", instance)
+	// Merge Go migrations into scripts
+	if len(l.goMigrations) > 0 {
+		src, err = l.goSource(src)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "sqlite3", driver)
+	return m, driver, err
 }
 
 // Migrate database to latest supported version, which is defined when create new LazyDB.
@@ -46,6 +77,11 @@ func (l *LazyDB) Migrate() (backupPath string, err error) {
 	return l.MigrateTo(l.schemaVersion)
 }
 
+// Migrate database to latest supported version with context, same as Migrate().
+func (l *LazyDB) MigrateContext(ctx context.Context) (backupPath string, err error) {
+	return l.MigrateToContext(ctx, l.schemaVersion)
+}
+
 // Migrate database to specified version.
 //
 // When version is 0, then it will migrate to latest version as possible,
@@ -54,35 +90,285 @@ func (l *LazyDB) Migrate() (backupPath string, err error) {
 // If backup directory is set, and migration is actually performed,
 // then this function will also return backup database path.
 // Otherwise empty string will be returned.
+//
+// Failure during migration will return *MigrationError.
+// If rollback on failure is enabled, the backup will be restored before return.
 func (l *LazyDB) MigrateTo(version uint) (backupPath string, err error) {
+	return l.MigrateToContext(context.Background(), version)
+}
+
+// Migrate database to specified version with context, same as MigrateTo().
+//
+// When context is cancelled, migration will be stopped after
+// current running version, and *MigrationError will be returned.
+func (l *LazyDB) MigrateToContext(ctx context.Context, version uint) (backupPath string, err error) {
+	target := func() (uint, error) {
+		if version > 0 {
+			return version, nil
+		}
+		return l.largestVersion()
+	}
+
+	return l.runMigration(ctx, target, func(m *migrate.Migrate) error {
+		// Perform migration depend on version is equals to 0
+		if version == 0 {
+			return m.Up()
+		}
+		return m.Migrate(version)
+	})
+}
+
+// Migrate database all the way down, i.e. apply all down migrations.
+//
+// Backup & rollback behavior is same as MigrateTo().
+func (l *LazyDB) MigrateDown() (backupPath string, err error) {
+	return l.MigrateDownContext(context.Background())
+}
+
+// Migrate database all the way down with context, same as MigrateDown().
+func (l *LazyDB) MigrateDownContext(ctx context.Context) (backupPath string, err error) {
+	target := func() (uint, error) {
+		return 0, nil
+	}
+
+	return l.runMigration(ctx, target, func(m *migrate.Migrate) error {
+		return m.Down()
+	})
+}
+
+// Migrate database by n steps. Positive n will migrate up, and negative n will migrate down.
+// If n exceeds available migrations, all available migrations will be applied without error.
+//
+// Backup & rollback behavior is same as MigrateTo().
+func (l *LazyDB) MigrateSteps(n int) (backupPath string, err error) {
+	return l.MigrateStepsContext(context.Background(), n)
+}
+
+// Migrate database by n steps with context, same as MigrateSteps().
+func (l *LazyDB) MigrateStepsContext(ctx context.Context, n int) (backupPath string, err error) {
+	target := func() (uint, error) {
+		return l.stepTarget(n)
+	}
+
+	return l.runMigration(ctx, target, func(m *migrate.Migrate) error {
+		err := m.Steps(n)
+
+		// Steps exceed available migrations, which are all applied
+		var short migrate.ErrShortLimit
+		if errors.As(err, &short) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Force database to be recorded as given version, and clear dirty flag.
+// No migration script will be executed, and no backup will be created.
+//
+// This function is used to repair database in dirty state after manual fix.
+// Use -1 to set database as no migration applied.
+func (l *LazyDB) ForceVersion(version int) (err error) {
+	unlock, err := l.lockWriter(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Prepare migration instance
+	l.mig, _, err = l.migrateInstance()
+	if err != nil {
+		return err
+	}
+
+	return l.mig.Force(version)
+}
+
+// Run migration by given function, with auto backup before migration,
+// hooks around migration, and rollback when migration failed.
+//
+// Function target should return the version that database will be after migration,
+// which used to determine backup is necessary.
+//
+// When context is cancelled, migration driver will refuse to start next version,
+// and running script will be interrupted & rolled back.
+// Database is left in last clean version.
+func (l *LazyDB) runMigration(ctx context.Context, target func() (uint, error), run func(m *migrate.Migrate) error) (backupPath string, err error) {
+	// Prevent context already cancelled
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// Prevent writes while migrating, include restore when migration failed
+	unlock, err := l.lockWriter(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
 	// Prepare migration instance
-	l.mig, err = l.migrateInstance()
+	var driver *migrationDriver
+	l.mig, driver, err = l.migrateInstance()
+	if err != nil {
+		return "", err
+	}
+	driver.ctx = ctx
+
+	// Ensure applied scripts are not modified
+	err = l.verifyChecksums(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Get target version
+	targetVer, err := target()
 	if err != nil {
 		return "", err
 	}
 
 	// Run backup
-	backupPath, err = l.autoBackup(l.mig)
+	backupPath, err = l.autoBackup(ctx, l.mig, targetVer)
 	if err != nil {
 		return backupPath, err
 	}
 
-	// Perform migration depend on version is equals to 0
-	if version == 0 {
-		err = l.mig.Up()
-	} else {
-		err = l.mig.Migrate(version)
+	// No hooks when version not changed
+	event := MigrationEvent{DB: l.db, From: versionOf(driver.current), To: targetVer, BackupPath: backupPath}
+	changed := event.From != event.To
+	driver.backupPath = backupPath
+
+	if changed {
+		err = callHook(l.hooks.BeforeAll, event)
 	}
 
-	// Early return if no error
 	if err == nil {
-		return backupPath, nil
+		err = run(l.mig)
+	}
+
+	err = l.migrationFailed(ctx, err, targetVer, backupPath)
+	if err != nil {
+		return backupPath, err
+	}
+
+	// Record checksums of applied scripts
+	err = l.recordChecksums(ctx)
+	if err != nil || !changed {
+		return backupPath, err
+	}
+
+	event.To = versionOf(driver.current)
+	return backupPath, callHook(l.hooks.AfterAll, event)
+}
+
+// Get version of database after migrating n steps from current version.
+//
+// If steps exceed available migrations, then largest/no version will be returned.
+func (l *LazyDB) stepTarget(n int) (uint, error) {
+	current, _, ok, err := readSchemaVersion(l.db)
+	if err != nil {
+		return 0, err
+	}
+
+	migrations, err := l.migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	// Find index of current version, -1 for no version
+	idx := -1
+	for i, m := range migrations {
+		if ok && m.Version == current {
+			idx = i
+		}
 	}
 
+	// Move by steps
+	idx += n
+	if idx < 0 || len(migrations) == 0 {
+		return 0, nil
+	}
+	if idx >= len(migrations) {
+		idx = len(migrations) - 1
+	}
+
+	return migrations[idx].Version, nil
+}
+
+// Handle error returned by migration, and perform rollback when necessary.
+//
+// Nil will be returned if no error or no changes applied,
+// otherwise *MigrationError will be returned.
+func (l *LazyDB) migrationFailed(ctx context.Context, err error, target uint, backupPath string) error {
 	// No changes applied, which is acceptable
-	if errors.Is(err, migrate.ErrNoChange) {
-		return backupPath, nil
+	if err == nil || errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	migErr := &MigrationError{Version: target, Err: err}
+
+	// Use dirty version as failed version, if any
+	if v, dirty, vErr := l.mig.Version(); vErr == nil && dirty {
+		migErr.Version = v
+	}
+
+	// No rollback required or available
+	if !l.rollback || backupPath == "" {
+		return migErr
+	}
+
+	// Restore backup that created before migration, even context is cancelled
+	restoreErr := l.restoreFrom(context.WithoutCancel(ctx), backupPath)
+
+	// Migration instance is bound to connection that closed by restore
+	l.mig = nil
+
+	if restoreErr != nil {
+		return errors.Join(migErr, fmt.Errorf("failed to restore backup: %w", restoreErr))
+	}
+
+	migErr.RestoredPath = backupPath
+	return migErr
+}
+
+// Read schema version that recorded by migration in given database, without modifying database.
+//
+// If database has no migration record, ok will be false and version will be 0.
+func readSchemaVersion(db *sql.DB) (version uint, dirty bool, ok bool, err error) {
+	if db == nil {
+		return 0, false, false, ErrNilDatabase
+	}
+
+	// Check migration table exists, as migration may never be performed
+	exist, err := tableExists(db, sqlite.DefaultMigrationsTable)
+	if err != nil || !exist {
+		return 0, false, false, err
+	}
+
+	// Get version record
+	var v int64
+	query := "SELECT version, dirty FROM " + sqlite.DefaultMigrationsTable + " LIMIT 1"
+	err = db.QueryRow(query).Scan(&v, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, false, nil
+	}
+	if err != nil {
+		return 0, false, false, err
+	}
+
+	// Negative version means nil version
+	if v < 0 {
+		return 0, dirty, false, nil
 	}
 
-	return backupPath, err
+	return uint(v), dirty, true, nil
+}
+
+// Check table with given name exists in database.
+func tableExists(db *sql.DB, name string) (bool, error) {
+	var ct int
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	err := db.QueryRow(query, name).Scan(&ct)
+	if err != nil {
+		return false, err
+	}
+	return ct > 0, nil
 }
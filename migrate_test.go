@@ -1,12 +1,15 @@
 package lazydb
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -20,6 +23,11 @@ var fsNormalTestV2 embed.FS
 //go:embed test_schema/normal_v3/*
 var fsNormalTestV3 embed.FS
 
+const dirBrokenTest = "test_schema/broken"
+
+//go:embed test_schema/broken/*
+var fsBrokenTest embed.FS
+
 // Function to prepare usable LazyDB object.
 // Purpose of function is to reuse code.
 //
@@ -235,3 +243,299 @@ func TestInvalidMigrate(t *testing.T) {
 	_, err = l4.Migrate()
 	assert.ErrorIs(t, err, ErrNilDatabase, "Not connected db should return ErrNilDatabase")
 }
+
+// Test failed migration, with & without rollback on failure.
+func TestMigrateFailure(t *testing.T) {
+	type testCase struct {
+		rollback    bool // Rollback on failure enabled
+		wantVersion int  // user_version after migration
+		wantDirty   bool // dirty flag after migration
+	}
+
+	tests := []testCase{
+		{false, 2, true},
+		{true, 2, false},
+	}
+
+	for idx, tt := range tests {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "broken.db")
+
+		// Prepare database of version 2
+		_, err := createOutdatedDb(path)
+		if err != nil {
+			t.Fatal("Failed to prepare outdated db: ", err)
+		}
+
+		opts := []DatabaseOption{
+			DbPath(path),
+			Migrate(fsBrokenTest, dirBrokenTest),
+			BackupDir(filepath.Join(tmpDir, "bk")),
+		}
+		if tt.rollback {
+			opts = append(opts, RollbackOnFailure())
+		}
+
+		db := New(opts...)
+		err = db.Connect()
+		if err != nil {
+			t.Fatal("Failed to connect: ", err)
+		}
+		defer db.Close()
+
+		// Migration must be failed at version 3
+		bk, err := db.Migrate()
+		assert.NotEqualValuesf(t, "", bk, "Case %d: Backup should be created", idx)
+
+		var migErr *MigrationError
+		if !assert.ErrorAsf(t, err, &migErr, "Case %d: Unexpected error type: %v", idx, err) {
+			continue
+		}
+		assert.EqualValuesf(t, 3, migErr.Version, "Case %d: Unexpected failed version", idx)
+		assert.NotNilf(t, migErr.Err, "Case %d: Original error should be kept", idx)
+
+		if tt.rollback {
+			assert.EqualValuesf(t, bk, migErr.RestoredPath, "Case %d: Unexpected restored path", idx)
+			assert.Nilf(t, db.mig, "Case %d: Migration instance of closed connection should be dropped", idx)
+		} else {
+			assert.EqualValuesf(t, "", migErr.RestoredPath, "Case %d: Unexpected restored path", idx)
+		}
+
+		// Check database state
+		ver, err := getUserVersion(db.DB())
+		assert.Nilf(t, err, "Case %d: Error when get user version", idx)
+		assert.EqualValuesf(t, tt.wantVersion, ver, "Case %d: Unexpected user version", idx)
+
+		_, dirty, _, err := readSchemaVersion(db.DB())
+		assert.Nilf(t, err, "Case %d: Error when read schema version", idx)
+		assert.EqualValuesf(t, tt.wantDirty, dirty, "Case %d: Unexpected dirty flag", idx)
+	}
+}
+
+// Test migrate all the way down, with backup created.
+func TestMigrateDown(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, _ := prepareTestLatestLazyDB(t, filepath.Join(tmpDir, "down.db"))
+	defer db.Close()
+	db.backupDir = filepath.Join(tmpDir, "bk")
+
+	bk, err := db.MigrateDown()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.NotEqualValuesf(t, "", bk, "Backup should be created before down migration")
+	assert.Truef(t, IsFileExist(bk), "Backup not exist: %s", bk)
+
+	// No version should be recorded
+	_, _, ok, err := readSchemaVersion(db.DB())
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.Falsef(t, ok, "Version should not be recorded after migrate down")
+
+	ver, _ := getUserVersion(db.DB())
+	assert.EqualValues(t, 0, ver)
+
+	// Migrate down again, no backup should be created
+	bk, err = db.MigrateDown()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValuesf(t, "", bk, "Backup should not be created when no version")
+}
+
+// Test migrate by steps.
+func TestMigrateSteps(t *testing.T) {
+	tempDir := t.TempDir()
+
+	type testCase struct {
+		fromLatest  bool // Start from latest version, otherwise start from new database
+		steps       int  // Steps to migrate
+		wantVersion int  // user_version after migration
+		wantBackup  bool // Backup should be created
+	}
+
+	tests := []testCase{
+		{true, -1, 2, true},
+		{true, -2, 1, true},
+		{true, -3, 0, true},
+		{true, 0, 3, false},
+		{false, 1, 1, false},
+		{false, 2, 2, false},
+		{false, 10, 3, false},
+	}
+
+	for idx, tt := range tests {
+		path := filepath.Join(tempDir, fmt.Sprintf("steps%d.db", idx))
+
+		var db *LazyDB
+		if tt.fromLatest {
+			db, _ = prepareTestLatestLazyDB(t, path)
+		} else {
+			db, _ = prepareTestLazyDB(t, path)
+		}
+		defer db.Close()
+		db.backupDir = filepath.Join(tempDir, "bk")
+
+		bk, err := db.MigrateSteps(tt.steps)
+		assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err)
+		assert.EqualValuesf(t, tt.wantBackup, bk != "", "Case %d: Unexpected backup: %s", idx, bk)
+
+		ver, _ := getUserVersion(db.DB())
+		assert.EqualValuesf(t, tt.wantVersion, ver, "Case %d: Unexpected user version", idx)
+	}
+}
+
+// Test migrate by steps that exceed available migrations, which all migrations should be applied.
+func TestMigrateStepsOvershoot(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for idx, rollback := range []bool{false, true} {
+		db, _ := prepareTestLazyDB(t, filepath.Join(tempDir, fmt.Sprintf("overshoot%d.db", idx)))
+		defer db.Close()
+
+		// Start from version 1
+		_, err := db.MigrateSteps(1)
+		if err != nil {
+			t.Fatal("Failed to prepare db of version 1: ", err)
+		}
+
+		db.backupDir = filepath.Join(tempDir, "bk")
+		db.rollback = rollback
+
+		bk, err := db.MigrateSteps(10)
+		assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err)
+		assert.NotEqualValuesf(t, "", bk, "Case %d: Backup should be created", idx)
+
+		ver, _ := getUserVersion(db.DB())
+		assert.EqualValuesf(t, 3, ver, "Case %d: Unexpected user version", idx)
+
+		version, dirty, _, err := readSchemaVersion(db.DB())
+		assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err)
+		assert.EqualValuesf(t, 3, version, "Case %d: Unexpected schema version", idx)
+		assert.Falsef(t, dirty, "Case %d: Database should not be dirty", idx)
+	}
+}
+
+// Test force version to repair dirty database.
+func TestForceVersion(t *testing.T) {
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "force.db")),
+		Migrate(fsBrokenTest, dirBrokenTest),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+
+	// Migration must be failed, which database become dirty
+	_, err = db.Migrate()
+	assert.NotNilf(t, err, "Broken migration should return error")
+
+	version, dirty, _, _ := readSchemaVersion(db.DB())
+	assert.EqualValues(t, 3, version)
+	assert.True(t, dirty)
+
+	// Force to previous version
+	err = db.ForceVersion(2)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	version, dirty, _, _ = readSchemaVersion(db.DB())
+	assert.EqualValues(t, 2, version)
+	assert.False(t, dirty)
+
+	// Migration should be available again
+	_, err = db.MigrateSteps(-1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	ver, _ := getUserVersion(db.DB())
+	assert.EqualValues(t, 1, ver)
+}
+
+// Test migration with context, which stopped when context is cancelled.
+func TestMigrateToContext(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, _ := prepareTestLazyDB(t, filepath.Join(tmpDir, "ctx.db"))
+	defer db.Close()
+
+	// Cancelled context should not perform any migration
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := db.MigrateToContext(ctx, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, _, ok, _ := readSchemaVersion(db.DB())
+	assert.Falsef(t, ok, "No migration should be performed with cancelled context")
+
+	// Cancel context after version 1 is applied
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	db.hooks.AfterVersion = func(e MigrationEvent) error {
+		if e.Version == 1 {
+			cancel()
+		}
+		return nil
+	}
+
+	_, err = db.MigrateToContext(ctx, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	var migErr *MigrationError
+	assert.ErrorAsf(t, err, &migErr, "Unexpected error type: %v", err)
+
+	version, dirty, _, _ := readSchemaVersion(db.DB())
+	assert.EqualValuesf(t, 1, version, "Migration should be stopped after version 1")
+	assert.Falsef(t, dirty, "Database should not be dirty")
+}
+
+// Test cancel migration while long running script is executing,
+// which script should be interrupted and database left in clean version.
+func TestMigrateContextInterrupt(t *testing.T) {
+	fsLong := fstest.MapFS{
+		"schema/1_init.up.sql":   {Data: []byte("CREATE TABLE numbers (n INTEGER)")},
+		"schema/1_init.down.sql": {Data: []byte("DROP TABLE numbers")},
+		"schema/2_fill.up.sql": {Data: []byte(`INSERT INTO numbers (n)
+			WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000000000) SELECT x FROM c`)},
+		"schema/2_fill.down.sql": {Data: []byte("DELETE FROM numbers")},
+		"schema/3_idx.up.sql":    {Data: []byte("CREATE INDEX idx_numbers ON numbers (n)")},
+		"schema/3_idx.down.sql":  {Data: []byte("DROP INDEX idx_numbers")},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "interrupt.db")),
+		Migrate(fsLong, "schema"),
+		Hooks(MigrationHooks{
+			BeforeVersion: func(e MigrationEvent) error {
+				if e.Version == 2 {
+					time.AfterFunc(50*time.Millisecond, cancel)
+				}
+				return nil
+			},
+		}),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	_, err = db.MigrateContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Lessf(t, time.Since(start), 10*time.Second, "Script should be interrupted")
+
+	version, dirty, _, _ := readSchemaVersion(db.DB())
+	assert.EqualValuesf(t, 1, version, "Migration should be stopped at version 1")
+	assert.Falsef(t, dirty, "Database should not be dirty")
+
+	var ct int
+	db.DB().QueryRow("SELECT COUNT(*) FROM numbers").Scan(&ct)
+	assert.EqualValuesf(t, 0, ct, "Interrupted script should be rolled back")
+
+	// Migration is available again
+	db.hooks = MigrationHooks{}
+	_, err = db.MigrateSteps(-1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+}
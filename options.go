@@ -1,14 +1,45 @@
 package lazydb
 
-import "io/fs"
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"log/slog"
+	"time"
+)
 
 // Final options for create database. Internal usage only.
 type databaseOpts struct {
-	DbPath        string // Absolute path of .db file
-	MigrateFS     fs.FS  // FS to be used for migration
-	MigrateDir    string // directory that contains migration sql files
-	SchemaVersion uint   // Schema version that using
-	BackupDir     string // directory that used to backup database file
+	DbPath        string          // Absolute path of .db file
+	MigrateFS     fs.FS           // FS to be used for migration
+	MigrateDir    string          // directory that contains migration sql files
+	SchemaVersion uint            // Schema version that using
+	BackupDir     string          // directory that used to backup database file
+	Retention     RetentionPolicy // policy to remove old backups in backup directory
+	Rollback      bool            // restore backup when migration failed
+	Strict        bool            // validate migration directory before migration
+
+	Checksums  bool                   // record & verify checksum of applied migration
+	OnMismatch func(ChecksumMismatch) // handler of checksum mismatch, nil for return error
+
+	GoMigrations map[uint]goMigration // migrations defined by Go functions, by version
+	Hooks        MigrationHooks       // callbacks invoked around migration
+
+	BackupProgress func(remaining, total int) // callback to report backup progress
+
+	StmtCacheSize int         // maximum number of cached prepared statements
+	Retry         RetryPolicy // policy to retry execution when database is busy
+
+	Tracer func(ctx context.Context, e QueryEvent) // function to receive every executed statement
+	Redact []string                                // columns that arguments should be redacted in tracing
+
+	Pragmas []pragma   // pragmas applied to every connection
+	Pool    PoolConfig // sizing & lifetime of connection pool
+	Readers int        // number of reader connections in split-pool mode, 0 for single pool
+
+	Storage   storageMode // storage of database, e.g. file, in-memory or temporary file
+	ReadOnly  bool        // open database file in read-only mode
+	Immutable bool        // treat read-only database file as immutable
 }
 
 // Option of database.
@@ -44,6 +75,43 @@ func Migrate(f fs.FS, dir string) DatabaseOption {
 	return migrateParam{f, dir}
 }
 
+// ---------------------------------------------------
+type goMigrationParam struct {
+	Version uint
+	Up      GoMigrationFunc
+	Down    GoMigrationFunc
+}
+
+func (g goMigrationParam) apply(opts *databaseOpts) {
+	if opts.GoMigrations == nil {
+		opts.GoMigrations = make(map[uint]goMigration)
+	}
+	opts.GoMigrations[g.Version] = goMigration{Up: g.Up, Down: g.Down}
+}
+
+// Register Go functions as migration of given version, which run alongside
+// migration scripts and tracked by same version number.
+//
+// Version must be positive and not used by any migration script, and function up must not be nil.
+// Otherwise, migration will return ErrInvalidMigration.
+//
+// Function down can be nil if downgrade is not supported. Same as script without down file,
+// migrating down through this version will only change the recorded version.
+func GoMigration(version uint, up, down GoMigrationFunc) DatabaseOption {
+	return goMigrationParam{version, up, down}
+}
+
+// ---------------------------------------------------
+
+func (h MigrationHooks) apply(opts *databaseOpts) {
+	opts.Hooks = h
+}
+
+// Invoke given hooks around every migration.
+func Hooks(hooks MigrationHooks) DatabaseOption {
+	return hooks
+}
+
 // ---------------------------------------------------
 
 type schemaVer uint
@@ -60,6 +128,36 @@ func Version(ver int) DatabaseOption {
 	return schemaVer(ver)
 }
 
+// ---------------------------------------------------
+type strictMigrations bool
+
+func (s strictMigrations) apply(opts *databaseOpts) {
+	opts.Strict = bool(s)
+}
+
+// Validate migration directory by ValidateMigrations() before any migration.
+// Migration will be refused with *ValidationError if any problem found.
+func StrictMigrations() DatabaseOption {
+	return strictMigrations(true)
+}
+
+// ---------------------------------------------------
+type checksumHandler func(ChecksumMismatch)
+
+func (v checksumHandler) apply(opts *databaseOpts) {
+	opts.Checksums = true
+	opts.OnMismatch = v
+}
+
+// Record checksum of every applied migration script in database,
+// and verify applied scripts are not modified before any migration.
+//
+// If onMismatch is nil, migration will be refused with *ChecksumError when mismatch found.
+// Otherwise onMismatch will be called for every mismatch, and migration continues.
+func VerifyChecksums(onMismatch func(ChecksumMismatch)) DatabaseOption {
+	return checksumHandler(onMismatch)
+}
+
 // ---------------------------------------------------
 type backupDir string
 
@@ -72,3 +170,151 @@ func (b backupDir) apply(opts *databaseOpts) {
 func BackupDir(path string) DatabaseOption {
 	return backupDir(path)
 }
+
+// ---------------------------------------------------
+
+// Policy to remove old backups inside backup directory.
+// Zero value of any field means no limit on that field.
+//
+// Only backups follow the naming of auto backup, i.e. {original_name}_bk_{time}.{ext},
+// will be considered. The most recent backup will never be removed.
+type RetentionPolicy struct {
+	KeepLast int           // Maximum number of backups to keep
+	MaxAge   time.Duration // Remove backups that older than this duration
+	MaxBytes int64         // Maximum total size of backups, oldest backups are removed first
+
+	// Invoked when removing old backups after auto backup failed, can be nil.
+	// Migration is not stopped by the failure, as backup is already created.
+	OnError func(err error)
+}
+
+// Check any limit is set in policy.
+func (r RetentionPolicy) limited() bool {
+	return r.KeepLast > 0 || r.MaxAge > 0 || r.MaxBytes > 0
+}
+
+func (r RetentionPolicy) apply(opts *databaseOpts) {
+	opts.Retention = r
+}
+
+// Remove old backups by given policy, after each successful auto backup.
+func Retention(policy RetentionPolicy) DatabaseOption {
+	return policy
+}
+
+// ---------------------------------------------------
+type rollbackOnFailure bool
+
+func (r rollbackOnFailure) apply(opts *databaseOpts) {
+	opts.Rollback = bool(r)
+}
+
+// Restore the backup that created before migration, when migration failed.
+//
+// This option only takes effect when backup directory is set,
+// and backup is actually created by migration.
+func RollbackOnFailure() DatabaseOption {
+	return rollbackOnFailure(true)
+}
+
+// ---------------------------------------------------
+type backupProgress func(remaining, total int)
+
+func (b backupProgress) apply(opts *databaseOpts) {
+	opts.BackupProgress = b
+}
+
+// Report progress of every backup, by calling given function after each backup step.
+//
+// The function will receive number of pages remaining to be copied,
+// and total number of pages in database.
+func BackupProgress(fn func(remaining, total int)) DatabaseOption {
+	return backupProgress(fn)
+}
+
+// ---------------------------------------------------
+type stmtCacheSize int
+
+func (s stmtCacheSize) apply(opts *databaseOpts) {
+	opts.StmtCacheSize = int(s)
+}
+
+// Set maximum number of prepared statements cached by Exec(), Query() & QueryRow().
+// Least recently used statement will be closed when cache is full.
+//
+// Use zero to disable caching, i.e. statement is closed after each use.
+func StmtCacheSize(size int) DatabaseOption {
+	return stmtCacheSize(size)
+}
+
+// ---------------------------------------------------
+type tracer func(ctx context.Context, e QueryEvent)
+
+func (t tracer) apply(opts *databaseOpts) {
+	opts.Tracer = t
+}
+
+// Call given function after every statement executed by Exec(), ExecMultiple(),
+// Query(), QueryRow() & each migration script.
+func Tracer(fn func(ctx context.Context, e QueryEvent)) DatabaseOption {
+	return tracer(fn)
+}
+
+// Log every executed statement by given logger, same as Tracer().
+// If logger is nil, slog.Default() will be used.
+//
+// Statement with error is logged as error level, otherwise debug level.
+func Logger(logger *slog.Logger) DatabaseOption {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return tracer(slogTracer(logger))
+}
+
+// ---------------------------------------------------
+type redactColumns []string
+
+func (r redactColumns) apply(opts *databaseOpts) {
+	opts.Redact = append(opts.Redact, r...)
+}
+
+// Replace arguments of given columns (case-insensitive) by "[REDACTED]" in tracing,
+// which prevent sensitive values being logged.
+//
+// Only affects Tracer() & Logger(), arguments bound to database are unchanged.
+func RedactColumns(columns ...string) DatabaseOption {
+	return redactColumns(columns)
+}
+
+// ---------------------------------------------------
+
+// Configuration of connection pool. Zero value of any field means default of database/sql,
+// i.e. no limit on open connections & lifetimes, and 2 idle connections.
+//
+// Exec(), ExecMultiple(), WithTx(), migrations & restore of LazyDB are serialized regardless of pool size,
+// writes through DB() directly are not.
+type PoolConfig struct {
+	MaxOpenConns    int           // Maximum number of open connections
+	MaxIdleConns    int           // Maximum number of idle connections
+	ConnMaxLifetime time.Duration // Maximum time a connection may be reused
+	ConnMaxIdleTime time.Duration // Maximum time a connection may be idle
+}
+
+func (p PoolConfig) apply(opts *databaseOpts) {
+	opts.Pool = p
+}
+
+// Use given configuration of connection pool, which replace the default of database/sql.
+func Pool(config PoolConfig) DatabaseOption {
+	return config
+}
+
+// Apply configuration to connection pool of given database.
+func (p PoolConfig) configure(db *sql.DB) {
+	db.SetMaxOpenConns(p.MaxOpenConns)
+	if p.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+}
@@ -0,0 +1,151 @@
+package lazydb
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// Direction of migration script.
+type Direction string
+
+const (
+	DirectionUp   Direction = "up"   // Script that upgrade schema
+	DirectionDown Direction = "down" // Script that downgrade schema
+)
+
+// Migration script that will be executed in migration.
+type PlannedScript struct {
+	Version   uint      // Version of migration
+	Name      string    // Identifier of migration, e.g. "init" for 1_init.up.sql
+	Direction Direction // Direction of script
+	File      string    // Filename of script, empty for Go migration
+	SQL       string    // Content of script, empty for Go migration
+	Go        bool      // Migration is defined by Go function
+}
+
+// Get scripts that would be executed when migrate database to target version, in execution order.
+//
+// When target is 0, then it will plan to latest version as possible, which is same as MigrateTo().
+// Empty slice will be returned if database is already in target version.
+//
+// This function will not modify the database, and no backup will be created.
+func (l *LazyDB) PlanMigration(target uint) ([]PlannedScript, error) {
+	// Prevent db is nil
+	if l.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	// Prevent empty migration directory
+	if l.migrateDir == "" {
+		return nil, ErrEmptyDir
+	}
+
+	// Get current version of database
+	current, dirty, applied, err := readSchemaVersion(l.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dirty database cannot be migrated
+	if dirty {
+		return nil, migrate.ErrDirty{Version: int(current)}
+	}
+
+	migrations, err := l.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine target version
+	if target == 0 {
+		target, err = l.largestVersion()
+		if err != nil {
+			return nil, err
+		}
+	} else if !containsVersion(migrations, target) {
+		return nil, fmt.Errorf("%w: %d", ErrVersionNotFound, target)
+	}
+
+	scripts := make([]PlannedScript, 0)
+
+	// Plan upgrade, by ascending order
+	if !applied || target > current {
+		for _, m := range migrations {
+			if (applied && m.Version <= current) || m.Version > target || !l.hasScript(m, DirectionUp) {
+				continue
+			}
+
+			script, err := l.plannedScript(m, DirectionUp, m.Up)
+			if err != nil {
+				return nil, err
+			}
+			scripts = append(scripts, script)
+		}
+
+		return scripts, nil
+	}
+
+	// Plan downgrade, by descending order
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > current || m.Version <= target || !l.hasScript(m, DirectionDown) {
+			continue
+		}
+
+		script, err := l.plannedScript(m, DirectionDown, m.Down)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, script)
+	}
+
+	return scripts, nil
+}
+
+// Check migration has script or Go function for given direction.
+func (l *LazyDB) hasScript(m Migration, direction Direction) bool {
+	switch {
+	case m.Go && direction == DirectionUp:
+		return l.goMigrations[m.Version].Up != nil
+	case m.Go:
+		return l.goMigrations[m.Version].Down != nil
+	case direction == DirectionUp:
+		return m.Up != ""
+	default:
+		return m.Down != ""
+	}
+}
+
+// Create PlannedScript by reading script content from migration fs.
+func (l *LazyDB) plannedScript(m Migration, direction Direction, file string) (PlannedScript, error) {
+	// Go migration has no script content
+	if m.Go {
+		return PlannedScript{Version: m.Version, Name: m.Name, Direction: direction, Go: true}, nil
+	}
+
+	content, err := fs.ReadFile(l.migrateFs, path.Join(l.migrateDir, file))
+	if err != nil {
+		return PlannedScript{}, err
+	}
+
+	return PlannedScript{
+		Version:   m.Version,
+		Name:      m.Name,
+		Direction: direction,
+		File:      file,
+		SQL:       string(content),
+	}, nil
+}
+
+// Check given version exists in migrations.
+func containsVersion(migrations []Migration, version uint) bool {
+	for _, m := range migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+	return false
+}
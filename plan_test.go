@@ -0,0 +1,87 @@
+package lazydb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Get files of given scripts.
+func scriptFiles(scripts []PlannedScript) []string {
+	result := make([]string, 0)
+	for _, s := range scripts {
+		result = append(result, s.File)
+	}
+	return result
+}
+
+func TestPlanMigration(t *testing.T) {
+	type testCase struct {
+		from    uint     // Version of database before planning, 0 for new database
+		target  uint     // Target version of plan
+		want    []string // Files of planned scripts
+		wantErr error    // Expected error
+	}
+
+	tests := []testCase{
+		// Upgrade
+		{0, 0, []string{"1_init.up.sql", "2_tbl.up.sql", "3_data.up.sql"}, nil},
+		{0, 2, []string{"1_init.up.sql", "2_tbl.up.sql"}, nil},
+		{1, 3, []string{"2_tbl.up.sql", "3_data.up.sql"}, nil},
+
+		// Downgrade
+		{3, 1, []string{"3_data.down.sql", "2_tbl.down.sql"}, nil},
+		{2, 1, []string{"2_tbl.down.sql"}, nil},
+
+		// No changes
+		{3, 0, []string{}, nil},
+		{2, 2, []string{}, nil},
+
+		// Invalid version
+		{1, 4, nil, ErrVersionNotFound},
+	}
+
+	for idx, tt := range tests {
+		db, _ := prepareTestLazyDB(t, filepath.Join(t.TempDir(), "plan.db"))
+		defer db.Close()
+
+		if tt.from > 0 {
+			_, err := db.MigrateTo(tt.from)
+			assert.Nilf(t, err, "Case %d: Failed to prepare database: %v", idx, err)
+		}
+
+		scripts, err := db.PlanMigration(tt.target)
+		if tt.wantErr != nil {
+			assert.ErrorIsf(t, err, tt.wantErr, "Case %d: Unexpected error: %v", idx, err)
+			continue
+		}
+
+		assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err)
+		assert.EqualValuesf(t, tt.want, scriptFiles(scripts), "Case %d: Unexpected plan", idx)
+
+		// Database should not be modified
+		ver, _ := getUserVersion(db.DB())
+		assert.EqualValuesf(t, tt.from, ver, "Case %d: Database modified by plan", idx)
+	}
+}
+
+func TestPlanMigrationContent(t *testing.T) {
+	db, _ := prepareTestLazyDB(t, filepath.Join(t.TempDir(), "plan.db"))
+	defer db.Close()
+
+	scripts, err := db.PlanMigration(1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []PlannedScript{
+		{Version: 1, Name: "init", Direction: DirectionUp, File: "1_init.up.sql", SQL: "PRAGMA user_version = 1"},
+	}, scripts)
+
+	// No migration table should be created by plan
+	_, _, ok, err := readSchemaVersion(db.DB())
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.Falsef(t, ok, "Plan should not record any version")
+
+	var ct int
+	db.DB().QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&ct)
+	assert.EqualValuesf(t, 0, ct, "Plan should not create migration table")
+}
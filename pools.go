@@ -0,0 +1,155 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strings"
+)
+
+// DSN parameter of writer pool, which acquire write lock when transaction begin,
+// instead of failing with SQLITE_BUSY when upgrading from read lock.
+const writerParams = "_txlock=immediate"
+
+// DSN parameter of reader pool, which reject any write.
+const readerParams = "_query_only=1"
+
+// ---------------------------------------------------
+type splitPools int
+
+func (s splitPools) apply(opts *databaseOpts) {
+	opts.Readers = int(s)
+}
+
+// Use separate pools for reading & writing, for better concurrency in WAL mode.
+//
+// Exec(), ExecMultiple(), WithTx(), migrations & backups use a single connection writer pool,
+// which begin transaction immediately. Query() & QueryRow() use a read-only pool
+// with given number of connections. Non-positive readers means number of CPUs.
+//
+// Journal mode will be set to WAL, unless JournalMode() is specified.
+func SplitPools(readers int) DatabaseOption {
+	if readers <= 0 {
+		readers = runtime.NumCPU()
+	}
+	return splitPools(readers)
+}
+
+// Check LazyDB is using separate pools for reading & writing.
+func (l *LazyDB) splitPools() bool {
+	return l.readers > 0
+}
+
+// Open a connection pool with given configuration, then test connection & verify pragmas.
+// The pool will be closed if any failure.
+func openPool(ctx context.Context, dsn string, pragmas []pragma, pool PoolConfig) (*sql.DB, error) {
+	connector, err := newConnector(dsn, pragmas)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(connector)
+	pool.configure(db)
+
+	// Test DB connection by ping
+	err = db.PingContext(ctx)
+	if err == nil {
+		// Ensure pragmas are applied
+		err = verifyPragmas(ctx, db, pragmas)
+	}
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Open writer & reader pools of split-pool mode.
+func (l *LazyDB) openSplitPools(ctx context.Context, dsn string) (writer, reader *sql.DB, err error) {
+	// Use WAL by default, so readers not blocking writer.
+	// In-memory & read-only database cannot change journal mode.
+	pragmas := l.pragmas
+	if l.storage != storageMemory && !l.readOnly && !hasPragma(pragmas, "journal_mode") {
+		pragmas = append([]pragma{{name: "journal_mode", value: "WAL", verify: true}}, pragmas...)
+	}
+
+	writerPool := l.pool
+	writerPool.MaxOpenConns = 1
+
+	writer, err = openPool(ctx, withParams(dsn, writerParams), pragmas, writerPool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Journal mode is persistent in database, which cannot be changed by read-only connection
+	var readerPragmas []pragma
+	for _, p := range pragmas {
+		if p.name != "journal_mode" {
+			readerPragmas = append(readerPragmas, p)
+		}
+	}
+
+	readerPool := l.pool
+	readerPool.MaxOpenConns, readerPool.MaxIdleConns = l.readers, l.readers
+
+	reader, err = openPool(ctx, withParams(dsn, readerParams), readerPragmas, readerPool)
+	if err != nil {
+		writer.Close()
+		return nil, nil, err
+	}
+
+	return writer, reader, nil
+}
+
+// Check pragma with given name exists.
+func hasPragma(pragmas []pragma, name string) bool {
+	for _, p := range pragmas {
+		if p.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Append parameters to DSN.
+func withParams(dsn, params string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + params
+	}
+	return dsn + "?" + params
+}
+
+// Get pool & statement cache for queries, which is reader pool in split-pool mode.
+func (l *LazyDB) queryPool() (*sql.DB, *stmtCache) {
+	if l.reader != nil {
+		return l.reader, l.readStmts
+	}
+	return l.db, l.stmts
+}
+
+// Get statistics of reader pool in split-pool mode.
+// Zero value will be returned if database is not connected or not in split-pool mode.
+func (l *LazyDB) ReaderPoolStats() sql.DBStats {
+	if l.reader == nil {
+		return sql.DBStats{}
+	}
+	return l.reader.Stats()
+}
+
+// Acquire lock of single writer, which released by calling returned function.
+// Waiting is stopped when context is cancelled.
+func (l *LazyDB) lockWriter(ctx context.Context) (unlock func(), err error) {
+	// Not connected by Connect(), e.g. created by struct literal
+	if l.writer == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.writer <- struct{}{}:
+		return func() { <-l.writer }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,188 @@
+package lazydb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPools(t *testing.T) {
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "split.db")),
+		SplitPools(3),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	// Pool sizes
+	assert.EqualValues(t, 1, db.PoolStats().MaxOpenConnections)
+	assert.EqualValues(t, 3, db.ReaderPoolStats().MaxOpenConnections)
+
+	// Journal mode is WAL by default
+	var mode string
+	err = db.DB().QueryRow("PRAGMA journal_mode").Scan(&mode)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, "wal", mode)
+
+	// Writes are visible to readers
+	_, err = db.Exec("INSERT INTO test_table (content, val) VALUES (?, ?)", "split", 10)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	var val int
+	row, err := db.QueryRow("SELECT val FROM test_table WHERE content = ?", "split")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	err = row.Scan(&val)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 10, val)
+
+	// Readers are not blocked by open write transaction
+	err = db.WithTx(context.Background(), func(tx *Tx) error {
+		_, err := tx.Exec("UPDATE test_table SET val = ? WHERE content = ?", 20, "split")
+		if err != nil {
+			return err
+		}
+
+		row, err := db.QueryRow("SELECT val FROM test_table WHERE content = ?", "split")
+		if err != nil {
+			return err
+		}
+		return row.Scan(&val)
+	})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValuesf(t, 10, val, "Uncommitted value should not be read")
+
+	// Reader pool is read-only
+	rows, err := db.Query("DELETE FROM test_table")
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+	}
+	assert.NotNilf(t, err, "Reader pool should reject write")
+}
+
+func TestSplitPoolsClose(t *testing.T) {
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "split.db")),
+		SplitPools(0),
+		JournalMode("DELETE"),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+
+	// Specified journal mode is not overridden
+	var mode string
+	err = db.DB().QueryRow("PRAGMA journal_mode").Scan(&mode)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, "delete", mode)
+	assert.Positive(t, db.ReaderPoolStats().MaxOpenConnections)
+
+	err = db.Close()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.Zero(t, db.ReaderPoolStats())
+	assert.Zero(t, db.StmtCacheStats())
+}
+
+// Ensure writes are not blocked by open rows, while writes are serialized.
+func TestSingleWriter(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "writer.db")), JournalMode("WAL"))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+	db.DB().Exec("CREATE TABLE other_table (content TEXT)")
+
+	// Write while iterating rows
+	done := make(chan error, 1)
+	go func() {
+		rows, err := db.Query("SELECT content FROM test_table")
+		if err != nil {
+			done <- err
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			_, err = db.Exec("INSERT INTO other_table (content) VALUES (?)", "abc")
+			if err != nil {
+				break
+			}
+		}
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+		assert.Nilf(t, err, "Unexpected error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Write is blocked by open rows")
+	}
+
+	// Write wait until transaction finished
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = db.WithTx(context.Background(), func(tx *Tx) error {
+		_, err := db.ExecContext(ctx, "DELETE FROM test_table")
+		return err
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualValues(t, 2, countTestTable(t, db))
+
+	_, err = db.Exec("DELETE FROM test_table")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 0, countTestTable(t, db))
+}
+
+// Ensure migrations & restore wait until transaction finished.
+func TestSingleWriterMigration(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db := New(
+		DbPath(filepath.Join(tmpDir, "writer.db")),
+		Migrate(fsNormalTestV2, dirNormalTestV2),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+
+	dest := filepath.Join(tmpDir, "backup.db")
+	err = db.BackupTo(dest)
+	if err != nil {
+		t.Fatal("Failed to backup: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = db.WithTx(context.Background(), func(tx *Tx) error {
+		_, err := db.MigrateContext(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		err = db.RestoreFromContext(ctx, dest)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		return nil
+	})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	_, _, ok, _ := readSchemaVersion(db.DB())
+	assert.Falsef(t, ok, "No migration should be performed while transaction is running")
+
+	// Migration is available after transaction finished
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+}
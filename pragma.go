@@ -0,0 +1,176 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLite pragma that applied to every connection.
+type pragma struct {
+	name   string // Name of pragma
+	value  string // Value to set
+	verify bool   // Check value is applied after connected
+}
+
+func (p pragma) apply(opts *databaseOpts) {
+	// Replace pragma with same name
+	for i := range opts.Pragmas {
+		if opts.Pragmas[i].name == p.name {
+			opts.Pragmas[i] = p
+			return
+		}
+	}
+	opts.Pragmas = append(opts.Pragmas, p)
+}
+
+// Set journal mode of database, e.g. "WAL", "DELETE", "TRUNCATE", "PERSIST", "MEMORY" or "OFF".
+func JournalMode(mode string) DatabaseOption {
+	return pragma{name: "journal_mode", value: mode, verify: true}
+}
+
+// Set synchronous level of every connection, e.g. "OFF", "NORMAL", "FULL" or "EXTRA".
+func Synchronous(level string) DatabaseOption {
+	return pragma{name: "synchronous", value: level, verify: true}
+}
+
+// Enable or disable foreign key constraints of every connection.
+func ForeignKeys(enabled bool) DatabaseOption {
+	return pragma{name: "foreign_keys", value: boolPragma(enabled), verify: true}
+}
+
+// Set time to wait for lock of database, before returning SQLITE_BUSY.
+func BusyTimeout(d time.Duration) DatabaseOption {
+	return pragma{name: "busy_timeout", value: strconv.FormatInt(d.Milliseconds(), 10), verify: true}
+}
+
+// Set cache size of every connection. Positive value is number of pages,
+// negative value is size in KiB.
+func CacheSize(n int) DatabaseOption {
+	return pragma{name: "cache_size", value: strconv.Itoa(n), verify: true}
+}
+
+// Set storage of temporary tables & indices, e.g. "DEFAULT", "FILE" or "MEMORY".
+func TempStore(mode string) DatabaseOption {
+	return pragma{name: "temp_store", value: mode, verify: true}
+}
+
+// Execute "PRAGMA {name} = {value}" on every connection.
+// The value is not verified after connected, as some pragma has no readable value.
+func Pragma(name, value string) DatabaseOption {
+	return pragma{name: name, value: value}
+}
+
+// Get value of boolean pragma.
+func boolPragma(b bool) string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
+}
+
+// Check pragma is safe to be embedded into statement.
+func (p pragma) validate() error {
+	valid := p.name != ""
+	for i := 0; i < len(p.name); i++ {
+		valid = valid && isIdentChar(p.name[i])
+	}
+	if !valid {
+		return fmt.Errorf("%w: invalid name %q", ErrInvalidPragma, p.name)
+	}
+	if p.value == "" || strings.ContainsAny(p.value, ";\x00") {
+		return fmt.Errorf("%w: invalid value %q of %s", ErrInvalidPragma, p.value, p.name)
+	}
+	return nil
+}
+
+// Get value that SQLite returns when query the pragma after it is applied.
+func (p pragma) expected() string {
+	value := strings.ToLower(strings.Trim(p.value, `'"`))
+
+	switch p.name {
+	case "synchronous":
+		levels := map[string]string{"off": "0", "normal": "1", "full": "2", "extra": "3"}
+		if v, ok := levels[value]; ok {
+			return v
+		}
+	case "temp_store":
+		modes := map[string]string{"default": "0", "file": "1", "memory": "2"}
+		if v, ok := modes[value]; ok {
+			return v
+		}
+	case "foreign_keys":
+		switch value {
+		case "on", "true", "yes":
+			return "1"
+		case "off", "false", "no":
+			return "0"
+		}
+	}
+
+	return value
+}
+
+// Connector that open SQLite connection with pragmas applied.
+//
+// Using connector instead of registered driver, so every LazyDB can have its own pragmas.
+type sqliteConnector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+// Create connector of given DSN, that apply pragmas to every new connection.
+func newConnector(dsn string, pragmas []pragma) (*sqliteConnector, error) {
+	for _, p := range pragmas {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	hook := func(conn *sqlite3.SQLiteConn) error {
+		for _, p := range pragmas {
+			_, err := conn.Exec(fmt.Sprintf("PRAGMA %s = %s", p.name, p.value), nil)
+			if err != nil {
+				return fmt.Errorf("failed to set pragma %s: %w", p.name, err)
+			}
+		}
+		return nil
+	}
+
+	return &sqliteConnector{dsn: dsn, driver: &sqlite3.SQLiteDriver{ConnectHook: hook}}, nil
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Check all pragmas are applied to database, by querying their values.
+func verifyPragmas(ctx context.Context, db *sql.DB, pragmas []pragma) error {
+	for _, p := range pragmas {
+		if !p.verify {
+			continue
+		}
+
+		var actual string
+		err := db.QueryRowContext(ctx, "PRAGMA "+p.name).Scan(&actual)
+		if err != nil {
+			return fmt.Errorf("failed to verify pragma %s: %w", p.name, err)
+		}
+
+		if !strings.EqualFold(actual, p.expected()) {
+			return fmt.Errorf("%w: %s is %q, expected %q", ErrPragmaNotApplied, p.name, actual, p.value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package lazydb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure pragmas are applied to every pooled connection.
+func TestPragmas(t *testing.T) {
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "pragma.db")),
+		JournalMode("WAL"),
+		Synchronous("FULL"),
+		ForeignKeys(true),
+		BusyTimeout(3*time.Second),
+		CacheSize(-4000),
+		TempStore("MEMORY"),
+		Pragma("recursive_triggers", "ON"),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+
+	type testCase struct {
+		name string
+		want string
+	}
+
+	tests := []testCase{
+		{"journal_mode", "wal"},
+		{"synchronous", "2"},
+		{"foreign_keys", "1"},
+		{"busy_timeout", "3000"},
+		{"cache_size", "-4000"},
+		{"temp_store", "2"},
+		{"recursive_triggers", "1"},
+	}
+
+	// Hold first connection, so query will use another connection
+	ctx := context.Background()
+	conn, err := db.DB().Conn(ctx)
+	if err != nil {
+		t.Fatal("Failed to get connection: ", err)
+	}
+	defer conn.Close()
+
+	for idx, tt := range tests {
+		var first, second string
+		err = conn.QueryRowContext(ctx, "PRAGMA "+tt.name).Scan(&first)
+		assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err)
+
+		err = db.DB().QueryRow("PRAGMA " + tt.name).Scan(&second)
+		assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err)
+
+		assert.EqualValuesf(t, tt.want, first, "Case %d: Unexpected value of %s", idx, tt.name)
+		assert.EqualValuesf(t, tt.want, second, "Case %d: Unexpected value of %s in another connection", idx, tt.name)
+	}
+}
+
+func TestPragmasInvalid(t *testing.T) {
+	type testCase struct {
+		opt  DatabaseOption
+		want error
+	}
+
+	tests := []testCase{
+		{Pragma("foreign_keys; DROP TABLE abc", "ON"), ErrInvalidPragma},
+		{Pragma("foreign_keys", "ON; DROP TABLE abc"), ErrInvalidPragma},
+		{Pragma("", "ON"), ErrInvalidPragma},
+		// SQLite ignores unknown journal mode
+		{JournalMode("UNKNOWN"), ErrPragmaNotApplied},
+	}
+
+	for idx, tt := range tests {
+		db := New(DbPath(filepath.Join(t.TempDir(), "invalid.db")), tt.opt)
+
+		err := db.Connect()
+		assert.ErrorIsf(t, err, tt.want, "Case %d: Unexpected error: %v", idx, err)
+		assert.Nilf(t, db.DB(), "Case %d: Database should not be kept", idx)
+	}
+}
+
+// Ensure later pragma with same name replace the earlier one.
+func TestPragmaOverride(t *testing.T) {
+	opt := defaultOpts()
+	ForeignKeys(true).apply(&opt)
+	JournalMode("WAL").apply(&opt)
+	ForeignKeys(false).apply(&opt)
+
+	assert.EqualValues(t, []pragma{
+		{"foreign_keys", "OFF", true},
+		{"journal_mode", "WAL", true},
+	}, opt.Pragmas)
+}
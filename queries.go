@@ -1,59 +1,287 @@
 package lazydb
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
 )
 
-// Fill A SQL statement contains '?' with arguments.
+// Fill A SQL statement contains placeholders with arguments.
 // This function behavior similar as prepare statement, however it will not consider the database config.
 //
+// Placeholders are numbered by same rule of SQLite, i.e. '?', '?NNN', ':name', '@name' & '$name',
+// then filled by same rule of driver binding: positional argument is bound by its position,
+// named argument (sql.Named or map[string]any) is bound by name.
+// Placeholders inside quoted literals & comments are not filled,
+// and placeholders without argument are kept as is.
+//
 // This function should be consider as a reference when logging/debugging,
 // it SHOULD NOT be used in construct real queries.
 //
-// If args contains unsupported type, panic will occur. This function only supports:
-//   - string
+// If args contains unsupported type, error wrapping ErrUnsupportedType will be returned.
+// This function supports:
+//   - nil, bool, string, []byte
 //   - int, int8, int16, int32, int64
 //   - uint, uint8, uint16, uint32, uint64
 //   - float32, float64
-func asFilledQuery(sql string, args ...any) string {
-	resultStr := sql
+//   - time.Time
+//   - driver.Valuer, and pointer of any supported type
+func asFilledQuery(query string, args ...any) (string, error) {
+	tokens := tokenizeQuery(query)
 
-	for _, item := range args {
-		switch itemVal := item.(type) {
-		case string:
-			resultStr = strings.Replace(resultStr, "?", `"`+itemVal+`"`, 1)
-
-		case int:
-			resultStr = strings.Replace(resultStr, "?", strconv.Itoa(itemVal), 1)
-		case int8:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatInt(int64(itemVal), 10), 1)
-		case int16:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatInt(int64(itemVal), 10), 1)
-		case int32:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatInt(int64(itemVal), 10), 1)
-		case int64:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatInt(itemVal, 10), 1)
-
-		case uint:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatUint(uint64(itemVal), 10), 1)
-		case uint8:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatUint(uint64(itemVal), 10), 1)
-		case uint16:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatUint(uint64(itemVal), 10), 1)
-		case uint32:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatUint(uint64(itemVal), 10), 1)
-		case uint64:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatUint(itemVal, 10), 1)
-
-		case float64:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatFloat(itemVal, 'G', -1, 64), 1)
-		case float32:
-			resultStr = strings.Replace(resultStr, "?", strconv.FormatFloat(float64(itemVal), 'G', -1, 32), 1)
+	// Index of each named placeholder
+	names := make(map[string][]int)
+	for _, tk := range tokens {
+		if tk.name != "" {
+			names[tk.name] = append(names[tk.name], tk.index)
+		}
+	}
+
+	// Format value of each placeholder index
+	values := make(map[int]string)
+	for i, item := range expandArgs(args) {
+		indexes := []int{i + 1}
+
+		if arg, ok := item.(sql.NamedArg); ok {
+			indexes, item = names[arg.Name], arg.Value
+		}
+
+		value, err := formatValue(item)
+		if err != nil {
+			return "", err
+		}
+
+		for _, idx := range indexes {
+			values[idx] = value
+		}
+	}
+
+	var sb strings.Builder
+	for _, tk := range tokens {
+		value, ok := values[tk.index]
+		if tk.index == 0 || !ok {
+			sb.WriteString(tk.text)
+			continue
+		}
+		sb.WriteString(value)
+	}
+
+	return sb.String(), nil
+}
+
+// Part of SQL statement, which is either placeholder or other text.
+type queryToken struct {
+	text  string // Original text
+	index int    // Index of placeholder start from 1, 0 for not placeholder
+	name  string // Name of named placeholder without prefix
+}
+
+// Split SQL statement into placeholders and other text,
+// with placeholders numbered by same rule of SQLite.
+func tokenizeQuery(query string) []queryToken {
+	var tokens []queryToken
+	maxIndex := 0
+	named := make(map[string]int) // Index of named placeholder, by text with prefix
+
+	start := 0 // Start of current text token
+	flush := func(end int) {
+		if end > start {
+			tokens = append(tokens, queryToken{text: query[start:end]})
+		}
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		// Skip quoted literal & identifier
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			i = skipUntil(query, i+1, string(closing))
+
+		// Skip comments
+		case strings.HasPrefix(query[i:], "--"):
+			i = skipUntil(query, i+2, "\n")
+		case strings.HasPrefix(query[i:], "/*"):
+			i = skipUntil(query, i+2, "*/")
+
+		case c == '?':
+			end := i + 1
+			for end < len(query) && '0' <= query[end] && query[end] <= '9' {
+				end++
+			}
+
+			// Numbered or next index
+			index := maxIndex + 1
+			if end > i+1 {
+				index, _ = strconv.Atoi(query[i+1 : end])
+			}
+			maxIndex = max(maxIndex, index)
+
+			flush(i)
+			tokens = append(tokens, queryToken{text: query[i:end], index: index})
+			i, start = end, end
+
+		case c == ':' || c == '@' || c == '$':
+			end := i + 1
+			for end < len(query) && isIdentChar(query[end]) {
+				end++
+			}
+
+			// Not a placeholder
+			if end == i+1 {
+				i++
+				continue
+			}
+
+			// Same placeholder share same index
+			text := query[i:end]
+			index, ok := named[text]
+			if !ok {
+				maxIndex++
+				index = maxIndex
+				named[text] = index
+			}
+
+			flush(i)
+			tokens = append(tokens, queryToken{text: text, index: index, name: query[i+1 : end]})
+			i, start = end, end
 
 		default:
-			panic("unsupported type")
+			i++
+		}
+	}
+
+	flush(len(query))
+	return tokens
+}
+
+// Get position after the first closing string found from start, or end of query if not found.
+func skipUntil(query string, start int, closing string) int {
+	idx := strings.Index(query[start:], closing)
+	if idx < 0 {
+		return len(query)
+	}
+	return start + idx + len(closing)
+}
+
+// Expand map[string]any inside args into sql.Named, sorted by name.
+// Other arguments are kept in same order.
+func expandArgs(args []any) []any {
+	expanded := make([]any, 0, len(args))
+
+	for _, item := range args {
+		m, ok := item.(map[string]any)
+		if !ok {
+			expanded = append(expanded, item)
+			continue
+		}
+
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			expanded = append(expanded, sql.Named(name, m[name]))
+		}
+	}
+
+	return expanded
+}
+
+// Check given character can be part of named parameter.
+func isIdentChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// Format value as SQLite literal, same as the value stored by driver.
+func formatValue(item any) (string, error) {
+	switch itemVal := item.(type) {
+	case nil:
+		return "NULL", nil
+
+	case driver.Valuer:
+		// Nil pointer of valuer is considered as NULL
+		if rv := reflect.ValueOf(itemVal); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return "NULL", nil
 		}
+
+		value, err := itemVal.Value()
+		if err != nil {
+			return "", err
+		}
+		return formatValue(value)
+
+	case string:
+		return "'" + strings.ReplaceAll(itemVal, "'", "''") + "'", nil
+	case []byte:
+		if itemVal == nil {
+			return "NULL", nil
+		}
+		return "X'" + strings.ToUpper(hex.EncodeToString(itemVal)) + "'", nil
+	case time.Time:
+		return formatValue(itemVal.Format(sqlite3.SQLiteTimestampFormats[0]))
+	}
+
+	// Match by kind, which also support named types & pointers
+	rv := reflect.ValueOf(item)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return formatValue(rv.Elem().Interface())
+
+	case reflect.Bool:
+		if rv.Bool() {
+			return "1", nil
+		}
+		return "0", nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+
+	case reflect.Float32, reflect.Float64:
+		return formatFloat(rv.Float(), rv.Type().Bits())
+
+	case reflect.String:
+		return formatValue(rv.String())
+	}
+
+	return "", fmt.Errorf("%w: %T", ErrUnsupportedType, item)
+}
+
+// Format float as SQLite REAL literal. NaN has no literal, and infinity is overflowed literal.
+func formatFloat(f float64, bitSize int) (string, error) {
+	switch {
+	case math.IsNaN(f):
+		return "", fmt.Errorf("%w: NaN", ErrUnsupportedType)
+	case math.IsInf(f, 1):
+		return "9e999", nil
+	case math.IsInf(f, -1):
+		return "-9e999", nil
+	}
+
+	// Integral value without decimal point is read as INTEGER
+	str := strconv.FormatFloat(f, 'G', -1, bitSize)
+	if !strings.ContainsAny(str, ".E") {
+		str += ".0"
 	}
-	return resultStr
+	return str, nil
 }
@@ -1,8 +1,10 @@
 package lazydb
 
 import (
+	"database/sql"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -39,7 +41,8 @@ func TestAsFilledQueryInt(t *testing.T) {
 	}
 
 	for idx, tt := range tests {
-		result := asFilledQuery(tt.sql, tt.args...)
+		result, err := asFilledQuery(tt.sql, tt.args...)
+		assert.Nilf(t, err, "Case %d: unexpected error: %v", idx, err)
 		assert.EqualValuesf(t, tt.want, result, "Case %d: incorrect result on int value", idx)
 	}
 }
@@ -76,7 +79,8 @@ func TestAsFilledQueryUInt(t *testing.T) {
 	}
 
 	for idx, tt := range tests {
-		result := asFilledQuery(tt.sql, tt.args...)
+		result, err := asFilledQuery(tt.sql, tt.args...)
+		assert.Nilf(t, err, "Case %d: unexpected error: %v", idx, err)
 		assert.EqualValuesf(t, tt.want, result, "Case %d: incorrect result on uint value", idx)
 	}
 }
@@ -90,7 +94,7 @@ func TestAsFilledQuery(t *testing.T) {
 
 	tests := []testCase{
 		// Single value for type check
-		{`SELECT * FROM test WHERE col1 = ?`, []any{"abc"}, `SELECT * FROM test WHERE col1 = "abc"`},
+		{`SELECT * FROM test WHERE col1 = ?`, []any{"abc"}, `SELECT * FROM test WHERE col1 = 'abc'`},
 
 		{`SELECT * FROM test WHERE col1 = ?`, []any{123}, `SELECT * FROM test WHERE col1 = 123`},
 		{`SELECT * FROM test WHERE col1 = ?`, []any{int8(123)}, `SELECT * FROM test WHERE col1 = 123`},
@@ -108,7 +112,7 @@ func TestAsFilledQuery(t *testing.T) {
 		{`SELECT * FROM test WHERE col1 = ?`, []any{float64(123.45)}, `SELECT * FROM test WHERE col1 = 123.45`},
 
 		// Multiple values
-		{`SELECT * FROM test WHERE col1 = ? AND col2 = ?`, []any{123, "abc"}, `SELECT * FROM test WHERE col1 = 123 AND col2 = "abc"`},
+		{`SELECT * FROM test WHERE col1 = ? AND col2 = ?`, []any{123, "abc"}, `SELECT * FROM test WHERE col1 = 123 AND col2 = 'abc'`},
 		{`SELECT * FROM test WHERE col1 = ? AND col2 = ?`, []any{123}, `SELECT * FROM test WHERE col1 = 123 AND col2 = ?`},
 
 		// No values
@@ -116,10 +120,114 @@ func TestAsFilledQuery(t *testing.T) {
 	}
 
 	for idx, tt := range tests {
-		result := asFilledQuery(tt.sql, tt.args...)
+		result, err := asFilledQuery(tt.sql, tt.args...)
+		assert.Nilf(t, err, "Case %d: unexpected error: %v", idx, err)
 		assert.EqualValuesf(t, tt.want, result, "Case %d: incorrect result", idx)
 	}
 
-	// Panic case
-	assert.Panics(t, func() { asFilledQuery(`SELECT * FROM test WHERE col1 = ? AND col2 = ?`, nil) })
+	// Unsupported type
+	_, err := asFilledQuery(`SELECT * FROM test WHERE col1 = ? AND col2 = ?`, 1, struct{}{})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	// NaN has no literal
+	_, err = asFilledQuery(`SELECT * FROM test WHERE col1 = ?`, math.NaN())
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestAsFilledQueryNamed(t *testing.T) {
+	type testCase struct {
+		sql  string
+		args []any
+		want string
+	}
+
+	tests := []testCase{
+		// Different prefix
+		{`SELECT * FROM test WHERE col1 = :a`, []any{sql.Named("a", 1)}, `SELECT * FROM test WHERE col1 = 1`},
+		{`SELECT * FROM test WHERE col1 = @a`, []any{sql.Named("a", 1)}, `SELECT * FROM test WHERE col1 = 1`},
+		{`SELECT * FROM test WHERE col1 = $a`, []any{sql.Named("a", 1)}, `SELECT * FROM test WHERE col1 = 1`},
+
+		// Map of named values, same name used multiple times
+		{`SELECT * FROM test WHERE col1 = :name AND col2 = :id OR col3 = :name`, []any{map[string]any{"id": 12, "name": "abc"}},
+			`SELECT * FROM test WHERE col1 = 'abc' AND col2 = 12 OR col3 = 'abc'`},
+
+		// Name prefix of other name
+		{`SELECT * FROM test WHERE col1 = :a AND col2 = :ab`, []any{sql.Named("ab", 2), sql.Named("a", 1)}, `SELECT * FROM test WHERE col1 = 1 AND col2 = 2`},
+
+		// Mixed with positional
+		{`SELECT * FROM test WHERE col1 = ? AND col2 = :a`, []any{"abc", sql.Named("a", 1)}, `SELECT * FROM test WHERE col1 = 'abc' AND col2 = 1`},
+
+		// Unknown name & quoted literal are kept
+		{`SELECT * FROM test WHERE col1 = :b AND col2 = ':a?'`, []any{sql.Named("a", 1), 2}, `SELECT * FROM test WHERE col1 = :b AND col2 = ':a?'`},
+
+		// Value contains placeholder
+		{`SELECT * FROM test WHERE col1 = ? AND col2 = ?`, []any{"?", 2}, `SELECT * FROM test WHERE col1 = '?' AND col2 = 2`},
+	}
+
+	for idx, tt := range tests {
+		result, err := asFilledQuery(tt.sql, tt.args...)
+		assert.Nilf(t, err, "Case %d: unexpected error: %v", idx, err)
+		assert.EqualValuesf(t, tt.want, result, "Case %d: incorrect result", idx)
+	}
+}
+
+func TestAsFilledQueryTypes(t *testing.T) {
+	type testCase struct {
+		sql  string
+		args []any
+		want string
+	}
+
+	str := "abc"
+	var nilStr *string
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []testCase{
+		{`SELECT ?`, []any{nil}, `SELECT NULL`},
+		{`SELECT ?, ?`, []any{true, false}, `SELECT 1, 0`},
+		{`SELECT ?`, []any{"it's"}, `SELECT 'it''s'`},
+		{`SELECT ?`, []any{[]byte{0xde, 0xad}}, `SELECT X'DEAD'`},
+		{`SELECT ?`, []any{tm}, `SELECT '2024-01-02 03:04:05+00:00'`},
+		{`SELECT ?, ?`, []any{&str, nilStr}, `SELECT 'abc', NULL`},
+		{`SELECT ?, ?`, []any{sql.NullInt64{Int64: 5, Valid: true}, sql.NullString{}}, `SELECT 5, NULL`},
+		{`SELECT ?`, []any{time.Duration(10)}, `SELECT 10`},
+		{`SELECT ?, ?, ?`, []any{float64(1), float32(-2), 1e21}, `SELECT 1.0, -2.0, 1E+21`},
+		{`SELECT ?, ?`, []any{math.Inf(1), float32(math.Inf(-1))}, `SELECT 9e999, -9e999`},
+	}
+
+	for idx, tt := range tests {
+		result, err := asFilledQuery(tt.sql, tt.args...)
+		assert.Nilf(t, err, "Case %d: unexpected error: %v", idx, err)
+		assert.EqualValuesf(t, tt.want, result, "Case %d: incorrect result", idx)
+	}
+}
+
+func TestAsFilledQueryTokens(t *testing.T) {
+	type testCase struct {
+		sql  string
+		args []any
+		want string
+	}
+
+	tests := []testCase{
+		// Literals, identifiers & comments are not filled
+		{`SELECT '?', "?", [?], ? -- ?`, []any{1}, `SELECT '?', "?", [?], 1 -- ?`},
+		{`SELECT 'it''s ?', ? /* ? */, ?`, []any{1, 2}, `SELECT 'it''s ?', 1 /* ? */, 2`},
+
+		// Numbered parameters
+		{`SELECT ?2, ?1, ?`, []any{1, 2, 3}, `SELECT 2, 1, 3`},
+		{`SELECT ?1, ?1`, []any{"a"}, `SELECT 'a', 'a'`},
+
+		// Named parameters numbered with positional
+		{`SELECT :a, ?, :a`, []any{sql.Named("a", 1), 2}, `SELECT 1, 2, 1`},
+
+		// Unterminated literal
+		{`SELECT ?, 'abc`, []any{1}, `SELECT 1, 'abc`},
+	}
+
+	for idx, tt := range tests {
+		result, err := asFilledQuery(tt.sql, tt.args...)
+		assert.Nilf(t, err, "Case %d: unexpected error: %v", idx, err)
+		assert.EqualValuesf(t, tt.want, result, "Case %d: incorrect result", idx)
+	}
 }
@@ -0,0 +1,213 @@
+package lazydb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Header of every SQLite database file.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// Suffixes of files that SQLite create beside database file.
+var sidecarSuffixes = []string{"-wal", "-shm", "-journal"}
+
+// Restore database from given backup file, then reconnect to restored database.
+//
+// The backup must be a valid SQLite database, with schema version not larger than
+// the largest version in migration fs & Go migrations (if any), and not in dirty state.
+//
+// The original database file is replaced atomically. If any step fails before replacement,
+// the original database will be untouched and connection will be kept.
+func (l *LazyDB) RestoreFrom(path string) (err error) {
+	return l.RestoreFromContext(context.Background(), path)
+}
+
+// Restore database from given backup file, same as RestoreFrom().
+//
+// The context is used in validation & copying of backup,
+// cancelled context will leave the original database untouched.
+func (l *LazyDB) RestoreFromContext(ctx context.Context, path string) (err error) {
+	// Prevent db is nil
+	if l.db == nil {
+		return ErrNilDatabase
+	}
+
+	// Prevent replacing read-only database
+	if l.readOnly {
+		return ErrReadOnly
+	}
+
+	// Prevent writes while database is replaced
+	unlock, err := l.lockWriter(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return l.restoreFrom(ctx, path)
+}
+
+// Restore database from given backup file, which caller must hold the writer lock.
+func (l *LazyDB) restoreFrom(ctx context.Context, path string) (err error) {
+	// Ensure backup is usable
+	err = l.validateBackup(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	// In-memory database has no file to replace
+	if l.storage == storageMemory {
+		return l.restoreMemory(ctx, path)
+	}
+
+	// Copy backup into temporary file beside database, so rename can be atomic
+	tmp, err := l.prepareRestoreFile(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp) // Cleanup when any failure before rename
+
+	// Write all content in WAL back to database, prevent data loss when sidecar removed
+	_, err = l.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)")
+	if err != nil {
+		return err
+	}
+
+	// Close connection before replace database file, with temporary directory kept
+	err = l.closePools()
+	if err != nil {
+		return err
+	}
+
+	// Replace database file
+	err = replaceDbFile(tmp, l.filePath())
+	if err != nil {
+		// Reconnect to original database
+		return errors.Join(fmt.Errorf("failed to replace database: %w", err), l.Connect())
+	}
+
+	return l.Connect()
+}
+
+// Check given backup file is a valid SQLite database,
+// with schema version that compatible to LazyDB migration.
+func (l *LazyDB) validateBackup(ctx context.Context, path string) error {
+	// Check file header
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(sqliteHeader))
+	_, err = io.ReadFull(f, header)
+	if err != nil || !bytes.Equal(header, sqliteHeader) {
+		return fmt.Errorf("%w: %s is not a SQLite database", ErrInvalidBackup, path)
+	}
+
+	// Open backup without any modification
+	bk, err := sql.Open(DatabaseType, path+"?_query_only=1")
+	if err != nil {
+		return err
+	}
+	defer bk.Close()
+
+	// Check database integrity
+	var result string
+	err = bk.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&result)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: %s", ErrInvalidBackup, result)
+	}
+
+	// Check schema version
+	version, dirty, _, err := readSchemaVersion(bk)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	if dirty {
+		return fmt.Errorf("%w: backup is dirty at version %d", ErrIncompatibleSchema, version)
+	}
+
+	// Skip version checking when no migration schema
+	if !l.hasMigrationSchema() {
+		return nil
+	}
+
+	latest, err := l.largestVersion()
+	if err != nil {
+		return fmt.Errorf("%w: failed to read migration schema: %v", ErrInvalidBackup, err)
+	}
+
+	if version > latest {
+		return fmt.Errorf("%w: backup version %d is newer than latest version %d", ErrIncompatibleSchema, version, latest)
+	}
+
+	return nil
+}
+
+// Copy backup into a temporary file in same directory of database.
+// Path of temporary file will be returned.
+func (l *LazyDB) prepareRestoreFile(ctx context.Context, path string) (tmp string, err error) {
+	f, err := os.CreateTemp(filepath.Dir(l.filePath()), filepath.Base(l.dbPath)+".restore-*")
+	if err != nil {
+		return "", err
+	}
+	tmp = f.Name()
+	f.Close()
+
+	// Open backup as source
+	src, err := sql.Open(DatabaseType, path+"?_query_only=1")
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	defer src.Close()
+
+	err = onlineBackup(ctx, src, tmp, l.backupProgress)
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	return tmp, nil
+}
+
+// Replace database file in dest by src file, with all sidecar files of dest removed.
+//
+// Database MUST be closed before calling this function.
+func replaceDbFile(src, dest string) error {
+	// Remove sidecar, prevent stale WAL/journal apply to new database
+	for _, suffix := range sidecarSuffixes {
+		err := os.Remove(dest + suffix)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return os.Rename(src, dest)
+}
+
+// Check LazyDB has migration schema, i.e. migration directory exists in migration fs,
+// or any Go migration is registered.
+func (l *LazyDB) hasMigrationSchema() bool {
+	if len(l.goMigrations) > 0 {
+		return true
+	}
+	if l.migrateFs == nil || l.migrateDir == "" {
+		return false
+	}
+
+	_, err := fs.Stat(l.migrateFs, l.migrateDir)
+	return !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,149 @@
+package lazydb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Count rows of test_table in given LazyDB.
+func countTestTable(t *testing.T, l *LazyDB) int {
+	var ct int
+	err := l.DB().QueryRow("SELECT COUNT(*) FROM test_table").Scan(&ct)
+	if err != nil {
+		t.Fatal("Failed to count test_table: ", err)
+	}
+	return ct
+}
+
+// Test restore database from a valid backup.
+func TestRestoreFrom(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Prepare database with data
+	db, _ := prepareTestLatestLazyDB(t, filepath.Join(tmpDir, "restore.db"))
+	defer db.Close()
+
+	_, err := db.DB().Exec("PRAGMA journal_mode=WAL")
+	assert.Nilf(t, err, "Error when enable WAL")
+	createDummyTable(db.DB())
+
+	// Backup, then modify original database
+	bk := filepath.Join(tmpDir, "bk", "restore_bk.db")
+	err = db.BackupTo(bk)
+	assert.Nilf(t, err, "Error when backup")
+
+	_, err = db.Exec("DELETE FROM test_table")
+	assert.Nilf(t, err, "Error when delete")
+	assert.EqualValues(t, 0, countTestTable(t, db))
+
+	// Restore
+	err = db.RestoreFrom(bk)
+	assert.Nilf(t, err, "Error when restore")
+	assert.EqualValues(t, 2, countTestTable(t, db))
+
+	// Schema version should be kept
+	ver, err := getUserVersion(db.DB())
+	assert.Nilf(t, err, "Error when get user version")
+	assert.EqualValues(t, 3, ver)
+
+	// Backup must not be modified
+	assert.Truef(t, IsFileExist(bk), "Backup removed after restore")
+}
+
+// Test restore with invalid backup, which original database must be untouched.
+func TestRestoreFromInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Prepare database with data
+	db, _ := prepareTestLatestLazyDB(t, filepath.Join(tmpDir, "restore.db"))
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	// Prepare newer backup from another database
+	newer := New(
+		DbPath(filepath.Join(tmpDir, "newer.db")),
+		Migrate(fsNormalTestV3, dirNormalTestV3),
+	)
+	newer.Connect()
+	newer.Migrate()
+	newer.Close()
+
+	// Prepare not database file
+	txt := filepath.Join(tmpDir, "not_db.db")
+	os.WriteFile(txt, []byte("this is not a database file"), 0644)
+
+	// Prepare dirty backup
+	dirty := filepath.Join(tmpDir, "dirty.db")
+	db.BackupTo(dirty)
+	dirtyDb := New(DbPath(dirty))
+	dirtyDb.Connect()
+	dirtyDb.DB().Exec("UPDATE schema_migrations SET dirty = 1")
+	dirtyDb.Close()
+
+	// Use older schema for LazyDB
+	db.migrateFs = fsNormalTestV2
+	db.migrateDir = dirNormalTestV2
+
+	tests := []struct {
+		path string
+		want error
+	}{
+		{txt, ErrInvalidBackup},
+		{filepath.Join(tmpDir, "newer.db"), ErrIncompatibleSchema},
+		{dirty, ErrIncompatibleSchema},
+		{filepath.Join(tmpDir, "not_exist.db"), os.ErrNotExist},
+	}
+
+	for idx, tt := range tests {
+		err := db.RestoreFrom(tt.path)
+		assert.ErrorIsf(t, err, tt.want, "Case %d: Unexpected error: %v", idx, err)
+
+		// Original database untouched & still connected
+		assert.EqualValuesf(t, 2, countTestTable(t, db), "Case %d: Original database modified", idx)
+	}
+
+	// No temporary file left
+	matches, _ := filepath.Glob(filepath.Join(tmpDir, "restore.db.restore-*"))
+	assert.Emptyf(t, matches, "Temporary file not removed")
+}
+
+// Ensure backup is rejected when migration schema cannot be read,
+// while restore without migration schema is allowed.
+func TestRestoreFromSchemaError(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	db, _ := prepareTestLatestLazyDB(t, filepath.Join(tmpDir, "restore.db"))
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	bk := filepath.Join(tmpDir, "restore_bk.db")
+	err := db.BackupTo(bk)
+	assert.Nilf(t, err, "Error when backup")
+
+	// Nested directory is invalid migration schema
+	db.migrateFs = fstest.MapFS{"schema/nested/1_init.up.sql": &fstest.MapFile{}}
+	db.migrateDir = "schema"
+
+	err = db.RestoreFrom(bk)
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+	assert.ErrorContains(t, err, ErrInvalidDir.Error())
+
+	// No migration schema
+	db.migrateDir = "not_exist"
+
+	err = db.RestoreFrom(bk)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 2, countTestTable(t, db))
+}
+
+// Ensure restore not performed when database is not connected.
+func TestRestoreFromNilDb(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "nil.db")))
+
+	err := db.RestoreFrom(filepath.Join(t.TempDir(), "nil_bk.db"))
+	assert.ErrorIs(t, err, ErrNilDatabase)
+}
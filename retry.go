@@ -0,0 +1,94 @@
+package lazydb
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Policy to retry execution when database is busy or locked by other connections.
+// Zero value means no retry.
+//
+// Delay before n-th retry is Backoff * 2^(n-1), limited by MaxBackoff,
+// then randomized by Jitter.
+type RetryPolicy struct {
+	MaxAttempts int           // Maximum number of attempts, include the first one
+	Backoff     time.Duration // Delay before first retry
+	MaxBackoff  time.Duration // Maximum delay between retries, zero for no limit
+	Jitter      float64       // Fraction of delay to randomize, clamped between 0 and 1
+
+	// Function called before each retry, with number of attempts failed and error of last attempt.
+	OnRetry func(attempt int, err error)
+}
+
+func (r RetryPolicy) apply(opts *databaseOpts) {
+	opts.Retry = r
+}
+
+// Retry Exec() & ExecMultiple() by given policy,
+// when database returns SQLITE_BUSY or SQLITE_LOCKED.
+func Retry(policy RetryPolicy) DatabaseOption {
+	return policy
+}
+
+// Check error is caused by database busy or locked, which is retryable.
+func isRetryable(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+// Get delay before retry, after given number of attempts failed.
+func (r RetryPolicy) delay(attempt int) time.Duration {
+	delay := r.Backoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if r.MaxBackoff > 0 && delay >= r.MaxBackoff {
+			break
+		}
+	}
+
+	if r.MaxBackoff > 0 && delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+
+	// Randomize delay in range of [delay*(1-jitter), delay*(1+jitter)],
+	// jitter larger than 1 will make delay negative
+	jitter := min(r.Jitter, 1)
+	if jitter > 0 {
+		delay = time.Duration(float64(delay) * (1 + jitter*(2*rand.Float64()-1)))
+	}
+
+	return delay
+}
+
+// Run given function, retry by policy when it returns retryable error.
+//
+// Error of last attempt will be returned. If context is cancelled while waiting,
+// the error will be joined with context error.
+func (r RetryPolicy) run(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.MaxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		if r.OnRetry != nil {
+			r.OnRetry(attempt, err)
+		}
+
+		// Wait before next attempt
+		timer := time.NewTimer(r.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
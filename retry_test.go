@@ -0,0 +1,224 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	type testCase struct {
+		err  error
+		want bool
+	}
+
+	tests := []testCase{
+		{nil, false},
+		{errors.New("abc"), false},
+		{sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{fmt.Errorf("wrapped: %w", sqlite3.Error{Code: sqlite3.ErrBusy}), true},
+	}
+
+	for idx, tt := range tests {
+		assert.EqualValuesf(t, tt.want, isRetryable(tt.err), "Case %d: Unexpected result", idx)
+	}
+}
+
+func TestRetryPolicyRun(t *testing.T) {
+	errBusy := sqlite3.Error{Code: sqlite3.ErrBusy}
+
+	type testCase struct {
+		maxAttempts int   // Maximum attempts of policy
+		failures    int   // Number of failed attempts before success
+		err         error // Error of failed attempts
+		wantCalls   int   // Expected number of function calls
+		wantRetries []int // Expected attempts reported to OnRetry
+		wantErr     error // Expected error
+	}
+
+	tests := []testCase{
+		// No retry by default
+		{0, 1, errBusy, 1, nil, errBusy},
+		// Success after retry
+		{3, 2, errBusy, 3, []int{1, 2}, nil},
+		// Attempts exhausted
+		{3, 5, errBusy, 3, []int{1, 2}, errBusy},
+		// Not retryable error
+		{3, 5, ErrEmptyStmt, 1, nil, ErrEmptyStmt},
+	}
+
+	for idx, tt := range tests {
+		var retries []int
+		policy := RetryPolicy{
+			MaxAttempts: tt.maxAttempts,
+			Backoff:     time.Millisecond,
+			Jitter:      0.5,
+			OnRetry:     func(attempt int, err error) { retries = append(retries, attempt) },
+		}
+
+		calls := 0
+		err := policy.run(context.Background(), func() error {
+			calls++
+			if calls <= tt.failures {
+				return tt.err
+			}
+			return nil
+		})
+
+		assert.ErrorIsf(t, err, tt.wantErr, "Case %d: Unexpected error: %v", idx, err)
+		assert.EqualValuesf(t, tt.wantCalls, calls, "Case %d: Unexpected calls", idx)
+		assert.EqualValuesf(t, tt.wantRetries, retries, "Case %d: Unexpected retries", idx)
+	}
+}
+
+// Ensure waiting for retry is stopped when context is cancelled.
+func TestRetryPolicyRunCancelled(t *testing.T) {
+	errBusy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	policy.OnRetry = func(attempt int, err error) { cancel() }
+
+	err := policy.run(ctx, func() error { return errBusy })
+	assert.ErrorIs(t, err, errBusy)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	type testCase struct {
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}
+
+	tests := []testCase{
+		{RetryPolicy{Backoff: 10 * time.Millisecond}, 1, 10 * time.Millisecond},
+		{RetryPolicy{Backoff: 10 * time.Millisecond}, 3, 40 * time.Millisecond},
+		{RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 25 * time.Millisecond}, 3, 25 * time.Millisecond},
+	}
+
+	for idx, tt := range tests {
+		assert.EqualValuesf(t, tt.want, tt.policy.delay(tt.attempt), "Case %d: Unexpected delay", idx)
+	}
+
+	// Jitter should keep delay in range
+	type jitterCase struct {
+		jitter   float64
+		min, max time.Duration
+	}
+
+	jitterTests := []jitterCase{
+		{0.2, 80 * time.Millisecond, 120 * time.Millisecond},
+		// Clamped to 1
+		{5, 0, 200 * time.Millisecond},
+		// Clamped to 0
+		{-1, 100 * time.Millisecond, 100 * time.Millisecond},
+	}
+
+	for idx, tt := range jitterTests {
+		policy := RetryPolicy{Backoff: 100 * time.Millisecond, Jitter: tt.jitter}
+		for i := 0; i < 20; i++ {
+			d := policy.delay(1)
+			assert.GreaterOrEqualf(t, d, tt.min, "Case %d: Delay too short", idx)
+			assert.LessOrEqualf(t, d, tt.max, "Case %d: Delay too long", idx)
+		}
+	}
+}
+
+// Ensure sqlite error is kept in error returned by ExecMultiple, so retry can detect it.
+func TestExecMultipleErrorWrapped(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "wrap.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	_, err = db.ExecMultiple([]ParamQuery{Param("INSERT INTO test_table (content) VALUES (?)", nil)})
+
+	var sqliteErr sqlite3.Error
+	assert.ErrorAsf(t, err, &sqliteErr, "Unexpected error type: %v", err)
+	assert.EqualValues(t, sqlite3.ErrConstraint, sqliteErr.Code)
+}
+
+// Ensure Exec() & ExecMultiple() retry when database is locked by other connection.
+func TestRetryBusy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "busy.db")
+
+	type testCase struct {
+		name string                 // Name of function
+		exec func(db *LazyDB) error // Function to execute write
+	}
+
+	tests := []testCase{
+		{"Exec", func(db *LazyDB) error {
+			_, err := db.Exec("INSERT INTO test_table (content, val) VALUES (?, ?)", "busy", 1)
+			return err
+		}},
+		{"ExecMultiple", func(db *LazyDB) error {
+			_, err := db.ExecMultiple([]ParamQuery{Param("INSERT INTO test_table (content, val) VALUES (?, ?)", "busy", 1)})
+			return err
+		}},
+	}
+
+	for idx, tt := range tests {
+		// Other connection that hold write lock
+		other, err := sql.Open(DatabaseType, path+"?_busy_timeout=0")
+		if err != nil {
+			t.Fatal("Failed to open other connection: ", err)
+		}
+		conn, err := other.Conn(context.Background())
+		if err != nil {
+			t.Fatal("Failed to get other connection: ", err)
+		}
+
+		var retries []error
+		db := New(
+			DbPath(path),
+			BusyTimeout(0),
+			Retry(RetryPolicy{
+				MaxAttempts: 5,
+				Backoff:     10 * time.Millisecond,
+				OnRetry: func(attempt int, err error) {
+					retries = append(retries, err)
+
+					// Release lock after first failure
+					conn.ExecContext(context.Background(), "COMMIT")
+				},
+			}),
+		)
+		err = db.Connect()
+		if err != nil {
+			t.Fatal("Failed to connect: ", err)
+		}
+		db.DB().Exec("DROP TABLE IF EXISTS test_table")
+		createDummyTable(db.DB())
+
+		_, err = conn.ExecContext(context.Background(), "BEGIN IMMEDIATE")
+		if err != nil {
+			t.Fatal("Failed to lock database: ", err)
+		}
+
+		err = tt.exec(db)
+		assert.Nilf(t, err, "Case %d (%s): Unexpected error: %v", idx, tt.name, err)
+		assert.Lenf(t, retries, 1, "Case %d (%s): Write should be retried once", idx, tt.name)
+		for _, e := range retries {
+			assert.Truef(t, isRetryable(e), "Case %d (%s): Unexpected retry error: %v", idx, tt.name, e)
+		}
+		assert.EqualValuesf(t, 3, countTestTable(t, db), "Case %d (%s): Unexpected row count", idx, tt.name)
+
+		db.Close()
+		conn.Close()
+		other.Close()
+	}
+}
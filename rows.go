@@ -0,0 +1,105 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+)
+
+// Iterate rows of query one by one, and scan each row into T.
+// Mapping of T is same as QueryAll().
+//
+// Rows are not materialised, and are closed when iteration completed or stopped early.
+// Any error will be yielded with zero value of T, and iteration will be stopped.
+func Rows[T any](l *LazyDB, query string, args ...any) iter.Seq2[T, error] {
+	return RowsContext[T](context.Background(), l, query, args...)
+}
+
+// Iterate rows of query with context, same as Rows().
+func RowsContext[T any](ctx context.Context, l *LazyDB, query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		rows, err := l.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		scan, err := rowScanner[T](rows)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		iterateRows(rows, scan, yield)
+	}
+}
+
+// Iterate rows of query one by one, and scan each row into map of column name to value.
+//
+// Behavior is same as Rows().
+func RowsMap(l *LazyDB, query string, args ...any) iter.Seq2[map[string]any, error] {
+	return RowsMapContext(context.Background(), l, query, args...)
+}
+
+// Iterate rows of query with context, same as RowsMap().
+func RowsMapContext(ctx context.Context, l *LazyDB, query string, args ...any) iter.Seq2[map[string]any, error] {
+	return func(yield func(map[string]any, error) bool) {
+		rows, err := l.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		scan := func() (map[string]any, error) {
+			values := make([]any, len(columns))
+			dest := make([]any, len(columns))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+
+			err := rows.Scan(dest...)
+			if err != nil {
+				return nil, err
+			}
+
+			item := make(map[string]any, len(columns))
+			for i, col := range columns {
+				item[col] = values[i]
+			}
+			return item, nil
+		}
+
+		iterateRows(rows, scan, yield)
+	}
+}
+
+// Yield every row scanned by given function, until rows exhausted, error occurs or yield returns false.
+func iterateRows[T any](rows *sql.Rows, scan func() (T, error), yield func(T, error) bool) {
+	var zero T
+
+	for rows.Next() {
+		item, err := scan()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		if !yield(item, nil) {
+			return
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		yield(zero, err)
+	}
+}
@@ -0,0 +1,67 @@
+package lazydb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRows(t *testing.T) {
+	db := prepareScanDb(t)
+	defer db.Close()
+
+	// Iterate all rows
+	var items []scanItem
+	for item, err := range Rows[scanItem](db, "SELECT id, content FROM test_table ORDER BY id") {
+		assert.Nilf(t, err, "Unexpected error: %v", err)
+		items = append(items, item)
+	}
+	assert.EqualValues(t, []scanItem{
+		{scanBase: scanBase{1}, Content: "abc"},
+		{scanBase: scanBase{2}, Content: "def"},
+	}, items)
+
+	// Stop early, rows should be closed so database can be written
+	for id, err := range Rows[int](db, "SELECT id FROM test_table ORDER BY id") {
+		assert.Nilf(t, err, "Unexpected error: %v", err)
+		assert.EqualValues(t, 1, id)
+		break
+	}
+
+	assert.EqualValues(t, 0, db.DB().Stats().InUse, "Connection should be released after break")
+
+	_, err := db.Exec("DELETE FROM test_table WHERE id = 1")
+	assert.Nilf(t, err, "Rows should be closed after break: %v", err)
+
+	// Error is yielded once
+	errCt := 0
+	for _, err := range Rows[scanItem](db, "SELECT id, content AS unknown FROM test_table") {
+		assert.ErrorIs(t, err, ErrUnmappedColumn)
+		errCt++
+	}
+	assert.EqualValues(t, 1, errCt, "Error should be yielded once")
+}
+
+func TestRowsMap(t *testing.T) {
+	db := prepareScanDb(t)
+	defer db.Close()
+
+	var items []map[string]any
+	for item, err := range RowsMap(db, "SELECT id, content, val FROM test_table ORDER BY id") {
+		assert.Nilf(t, err, "Unexpected error: %v", err)
+		items = append(items, item)
+	}
+	assert.EqualValues(t, []map[string]any{
+		{"id": int64(1), "content": "abc", "val": int64(123)},
+		{"id": int64(2), "content": "def", "val": nil},
+	}, items)
+
+	// Error when database not connected
+	errCt := 0
+	for _, err := range RowsMap(New(DbPath(filepath.Join(t.TempDir(), "nil.db"))), "SELECT 1") {
+		assert.ErrorIs(t, err, ErrNilDatabase)
+		errCt++
+	}
+	assert.EqualValues(t, 1, errCt, "Error should be yielded once")
+}
@@ -0,0 +1,221 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tag of struct field, which specify column name to be mapped.
+// Use "-" to ignore the field.
+const scanTag = "db"
+
+// Cache of column to field mapping, by struct type.
+var fieldMaps sync.Map // map[reflect.Type]map[string][]int
+
+// Query all rows, and scan each row into T.
+//
+// If T is a struct, columns are mapped to fields by `db:"..."` tag,
+// or field name in case-insensitive when tag is absent.
+// Fields of embedded structs are also mapped. Otherwise, query must return single column.
+func QueryAll[T any](l *LazyDB, query string, args ...any) ([]T, error) {
+	return QueryAllContext[T](context.Background(), l, query, args...)
+}
+
+// Query all rows with context, same as QueryAll().
+func QueryAllContext[T any](ctx context.Context, l *LazyDB, query string, args ...any) ([]T, error) {
+	rows, err := l.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scan, err := rowScanner[T](rows)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]T, 0)
+	for rows.Next() {
+		item, err := scan()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+
+	return results, rows.Err()
+}
+
+// Query first row, and scan it into T. Mapping of T is same as QueryAll().
+//
+// sql.ErrNoRows will be returned when query has no result.
+func QueryOne[T any](l *LazyDB, query string, args ...any) (T, error) {
+	return QueryOneContext[T](context.Background(), l, query, args...)
+}
+
+// Query first row with context, same as QueryOne().
+func QueryOneContext[T any](ctx context.Context, l *LazyDB, query string, args ...any) (T, error) {
+	var zero T
+
+	rows, err := l.QueryContext(ctx, query, args...)
+	if err != nil {
+		return zero, err
+	}
+	defer rows.Close()
+
+	scan, err := rowScanner[T](rows)
+	if err != nil {
+		return zero, err
+	}
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return zero, err
+		}
+		return zero, sql.ErrNoRows
+	}
+
+	return scan()
+}
+
+// Create function that scan current row of rows into T.
+// Mapping of columns is resolved once, and reused for every row.
+func rowScanner[T any](rows *sql.Rows) (func() (T, error), error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeFor[T]()
+
+	// Scan single column directly
+	if !isStructDest(typ) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("query returns %d columns, but %v can only scan 1 column", len(columns), typ)
+		}
+
+		return func() (T, error) {
+			var item T
+			err := rows.Scan(&item)
+			return item, err
+		}, nil
+	}
+
+	// Resolve field of each column
+	fields := fieldMap(typ)
+	indexes := make([][]int, len(columns))
+	for i, col := range columns {
+		index, ok := fields[strings.ToLower(col)]
+		if !ok {
+			return nil, fmt.Errorf("%w: column %q in %v", ErrUnmappedColumn, col, typ)
+		}
+		indexes[i] = index
+	}
+
+	return func() (T, error) {
+		var item T
+		v := reflect.ValueOf(&item).Elem()
+
+		dest := make([]any, len(indexes))
+		for i, index := range indexes {
+			dest[i] = fieldByIndex(v, index).Addr().Interface()
+		}
+
+		err := rows.Scan(dest...)
+		return item, err
+	}, nil
+}
+
+// Check values of given type should be mapped by fields,
+// instead of scanning as single value.
+func isStructDest(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ == reflect.TypeFor[time.Time]() {
+		return false
+	}
+	return !reflect.PointerTo(typ).Implements(reflect.TypeFor[sql.Scanner]())
+}
+
+// Get mapping of lower-cased column name to field index of given struct type.
+func fieldMap(typ reflect.Type) map[string][]int {
+	if m, ok := fieldMaps.Load(typ); ok {
+		return m.(map[string][]int)
+	}
+
+	m := make(map[string][]int)
+	addFields(m, typ, nil)
+
+	fieldMaps.Store(typ, m)
+	return m
+}
+
+// Add fields of struct type into mapping, include fields of embedded structs.
+// Fields of outer struct take precedence over fields of embedded structs.
+func addFields(m map[string][]int, typ reflect.Type, parent []int) {
+	var embedded []reflect.StructField
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get(scanTag)
+		if tag == "-" {
+			continue
+		}
+
+		index := append(append([]int{}, parent...), i)
+
+		// Flatten embedded struct without tag later
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			// Pointer of unexported type cannot be allocated
+			if f.Anonymous && !f.IsExported() {
+				continue
+			}
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag == "" && isStructDest(ft) {
+			f.Index = index
+			embedded = append(embedded, f)
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		name = strings.ToLower(name)
+
+		if _, ok := m[name]; !ok {
+			m[name] = index
+		}
+	}
+
+	for _, f := range embedded {
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		addFields(m, ft, f.Index)
+	}
+}
+
+// Get field of struct by index, with nil pointer of embedded struct allocated.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, idx := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v
+}
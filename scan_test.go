@@ -0,0 +1,107 @@
+package lazydb
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Embedded struct for testing scanning.
+type scanBase struct {
+	ID int `db:"id"`
+}
+
+// Struct for testing scanning.
+type scanItem struct {
+	scanBase
+	Content string         `db:"content"`
+	Val     sql.NullInt64  `db:"val"`
+	Note    sql.NullString // Mapped by field name
+	Ignored string         `db:"-"`
+}
+
+// Exported embedded struct for testing scanning by pointer.
+type ScanPtrBase struct {
+	ID int `db:"id"`
+}
+
+// Struct with embedded pointer for testing scanning.
+type scanPtrItem struct {
+	*ScanPtrBase
+	Content string `db:"content"`
+}
+
+// Prepare connected LazyDB with test_table.
+func prepareScanDb(t *testing.T) *LazyDB {
+	db := New(DbPath(filepath.Join(t.TempDir(), "scan.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+
+	_, err = db.DB().Exec(`CREATE TABLE test_table (id INTEGER PRIMARY KEY, content TEXT NOT NULL, val INT, note TEXT);
+		INSERT INTO test_table (id, content, val, note) VALUES (1, 'abc', 123, NULL), (2, 'def', NULL, 'n');`)
+	if err != nil {
+		t.Fatal("Failed to prepare table: ", err)
+	}
+
+	return db
+}
+
+func TestQueryAll(t *testing.T) {
+	db := prepareScanDb(t)
+	defer db.Close()
+
+	// Struct with embedded struct & sql.Null*
+	items, err := QueryAll[scanItem](db, "SELECT id, content, val, note FROM test_table ORDER BY id")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []scanItem{
+		{scanBase{1}, "abc", sql.NullInt64{Int64: 123, Valid: true}, sql.NullString{}, ""},
+		{scanBase{2}, "def", sql.NullInt64{}, sql.NullString{String: "n", Valid: true}, ""},
+	}, items)
+
+	// Embedded pointer
+	ptrItems, err := QueryAll[scanPtrItem](db, "SELECT id, content FROM test_table WHERE id = ?", 2)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []scanPtrItem{{&ScanPtrBase{2}, "def"}}, ptrItems)
+
+	// Single column
+	contents, err := QueryAll[string](db, "SELECT content FROM test_table ORDER BY id")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []string{"abc", "def"}, contents)
+
+	// No rows
+	empty, err := QueryAll[scanItem](db, "SELECT id, content FROM test_table WHERE id > 10")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []scanItem{}, empty)
+
+	// Column without matching field
+	_, err = QueryAll[scanItem](db, "SELECT id, content AS unknown FROM test_table")
+	assert.ErrorIs(t, err, ErrUnmappedColumn)
+
+	// Multiple column for single value
+	_, err = QueryAll[string](db, "SELECT id, content FROM test_table")
+	assert.NotNil(t, err)
+}
+
+func TestQueryOne(t *testing.T) {
+	db := prepareScanDb(t)
+	defer db.Close()
+
+	item, err := QueryOne[scanItem](db, "SELECT id, content FROM test_table WHERE content = ?", "def")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, scanItem{scanBase: scanBase{2}, Content: "def"}, item)
+
+	ct, err := QueryOne[int](db, "SELECT COUNT(*) FROM test_table")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 2, ct)
+
+	_, err = QueryOne[scanItem](db, "SELECT id, content FROM test_table WHERE id > 10")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Nil database
+	_, err = QueryOne[int](&LazyDB{}, "SELECT 1")
+	assert.ErrorIs(t, err, ErrNilDatabase)
+}
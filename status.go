@@ -0,0 +1,63 @@
+package lazydb
+
+// Migration status of database.
+type MigrationStatus struct {
+	Version uint // Current schema version of database, 0 if no migration applied
+	Dirty   bool // Database is in dirty state, i.e. last migration failed at Version
+	Latest  uint // Largest schema version available in migration fs & Go migrations
+
+	Applied []Migration // Migrations that applied to database, include dirty version
+	Pending []Migration // Migrations that not yet applied to database
+}
+
+// Get migration status of database, by comparing recorded version
+// with migration scripts inside migration fs.
+//
+// This function will not modify the database.
+func (l *LazyDB) MigrationStatus() (*MigrationStatus, error) {
+	// Prevent db is nil
+	if l.db == nil {
+		return nil, ErrNilDatabase
+	}
+
+	// Prevent empty migration directory
+	if l.migrateDir == "" {
+		return nil, ErrEmptyDir
+	}
+
+	// Get current version of database
+	version, dirty, applied, err := readSchemaVersion(l.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get available migrations
+	latest, err := l.largestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := l.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{
+		Version: version,
+		Dirty:   dirty,
+		Latest:  latest,
+		Applied: make([]Migration, 0),
+		Pending: make([]Migration, 0),
+	}
+
+	// Split migrations by current version
+	for _, m := range migrations {
+		if applied && m.Version <= version {
+			status.Applied = append(status.Applied, m)
+		} else {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,68 @@
+package lazydb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Get versions of given migrations.
+func migrationVersions(migrations []Migration) []uint {
+	result := make([]uint, 0)
+	for _, m := range migrations {
+		result = append(result, m.Version)
+	}
+	return result
+}
+
+func TestMigrationStatus(t *testing.T) {
+	db, _ := prepareTestLazyDB(t, filepath.Join(t.TempDir(), "status.db"))
+	defer db.Close()
+
+	// Case: No migration performed
+	status, err := db.MigrationStatus()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 0, status.Version)
+	assert.EqualValues(t, false, status.Dirty)
+	assert.EqualValues(t, 3, status.Latest)
+	assert.EqualValues(t, []uint{}, migrationVersions(status.Applied))
+	assert.EqualValues(t, []uint{1, 2, 3}, migrationVersions(status.Pending))
+
+	// Case: Partly migrated
+	_, err = db.MigrateTo(2)
+	assert.Nilf(t, err, "Unexpected error when migrate: %v", err)
+
+	status, err = db.MigrationStatus()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 2, status.Version)
+	assert.EqualValues(t, 3, status.Latest)
+	assert.EqualValues(t, []uint{1, 2}, migrationVersions(status.Applied))
+	assert.EqualValues(t, []uint{3}, migrationVersions(status.Pending))
+	assert.EqualValues(t, Migration{Version: 2, Name: "tbl", Up: "2_tbl.up.sql", Down: "2_tbl.down.sql"}, status.Applied[1])
+
+	// Case: Fully migrated
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error when migrate: %v", err)
+
+	status, err = db.MigrationStatus()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 3, status.Version)
+	assert.EqualValues(t, []uint{1, 2, 3}, migrationVersions(status.Applied))
+	assert.EqualValues(t, []uint{}, migrationVersions(status.Pending))
+}
+
+func TestMigrationStatusInvalid(t *testing.T) {
+	// Case: Not connected
+	db := New(Migrate(fsNormalTestV3, dirNormalTestV3))
+	_, err := db.MigrationStatus()
+	assert.ErrorIs(t, err, ErrNilDatabase)
+
+	// Case: Empty migration directory
+	db = New(DbPath(filepath.Join(t.TempDir(), "status.db")), Migrate(fsNormalTestV3, ""))
+	db.Connect()
+	defer db.Close()
+
+	_, err = db.MigrationStatus()
+	assert.ErrorIs(t, err, ErrEmptyDir)
+}
@@ -0,0 +1,190 @@
+package lazydb
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// Statistics of prepared statement cache.
+type StmtCacheStats struct {
+	Hits   uint64 // Number of queries that reuse cached statement
+	Misses uint64 // Number of queries that prepare new statement
+	Size   int    // Number of statements currently cached
+}
+
+// Prepared statement that stored in cache.
+//
+// Statement is only closed when it is evicted/cache closed, and no one is using it.
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int  // Number of callers that currently using statement
+	evicted bool // Statement is removed from cache, and should be closed after use
+}
+
+// Bounded LRU cache of prepared statements, keyed by query text.
+// Safe for concurrent use.
+type stmtCache struct {
+	mu       sync.Mutex
+	capacity int                      // Maximum number of cached statements, non-positive for no caching
+	order    *list.List               // Cached statements, most recently used at front
+	items    map[string]*list.Element // Element of order by query text
+	hits     uint64
+	misses   uint64
+}
+
+// Create a new statement cache with given capacity.
+func newStmtCache(capacity int) *stmtCache {
+	return &stmtCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get prepared statement of given query, prepare it by db if not cached.
+//
+// Caller MUST call release() after statement is no longer used.
+func (c *stmtCache) get(ctx context.Context, db *sql.DB, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if el, ok := c.items[query]; ok {
+		c.hits++
+		c.order.MoveToFront(el)
+
+		cs := el.Value.(*cachedStmt)
+		cs.refs++
+		c.mu.Unlock()
+		return cs, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Prepare outside lock, prevent slow prepare blocking other queries
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Statement may be prepared by others at same time
+	if el, ok := c.items[query]; ok {
+		stmt.Close()
+
+		cs := el.Value.(*cachedStmt)
+		cs.refs++
+		return cs, nil
+	}
+
+	cs := &cachedStmt{query: query, stmt: stmt, refs: 1}
+
+	// No caching, statement will be closed after use
+	if c.capacity <= 0 {
+		cs.evicted = true
+		return cs, nil
+	}
+
+	c.items[query] = c.order.PushFront(cs)
+
+	// Evict least recently used statements
+	for c.order.Len() > c.capacity {
+		c.evict(c.order.Back())
+	}
+
+	return cs, nil
+}
+
+// Get cached statement of given query without preparing, false if not cached.
+// Only hit is counted, as statement that not cached is not served by cache.
+//
+// Caller MUST call release() after statement is no longer used.
+func (c *stmtCache) lookup(query string) (*cachedStmt, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[query]
+	if !ok {
+		return nil, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(el)
+
+	cs := el.Value.(*cachedStmt)
+	cs.refs++
+	return cs, true
+}
+
+// Release statement that obtained by get() or lookup().
+func (c *stmtCache) release(cs *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cs.refs--
+	if cs.evicted && cs.refs == 0 {
+		cs.stmt.Close()
+	}
+}
+
+// Remove element from cache. Statement is closed if no one is using it.
+//
+// Caller MUST hold the lock.
+func (c *stmtCache) evict(el *list.Element) {
+	cs := c.order.Remove(el).(*cachedStmt)
+	delete(c.items, cs.query)
+
+	cs.evicted = true
+	if cs.refs == 0 {
+		cs.stmt.Close()
+	}
+}
+
+// Get statistics of cache.
+func (c *stmtCache) stats() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return StmtCacheStats{Hits: c.hits, Misses: c.misses, Size: c.order.Len()}
+}
+
+// Close all cached statements. Statements in use will be closed after released.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+	for el := c.order.Back(); el != nil; el = c.order.Back() {
+		cs := el.Value.(*cachedStmt)
+		if cs.refs == 0 {
+			errs = append(errs, cs.stmt.Close())
+		}
+
+		c.order.Remove(el)
+		delete(c.items, cs.query)
+		cs.evicted = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// Get statistics of prepared statement cache.
+// In split-pool mode, statistics of both pools are summed.
+// Zero value will be returned if database is not connected.
+func (l *LazyDB) StmtCacheStats() StmtCacheStats {
+	var stats StmtCacheStats
+	for _, c := range []*stmtCache{l.stmts, l.readStmts} {
+		if c == nil {
+			continue
+		}
+
+		s := c.stats()
+		stats.Hits += s.Hits
+		stats.Misses += s.Misses
+		stats.Size += s.Size
+	}
+	return stats
+}
@@ -0,0 +1,151 @@
+package lazydb
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test hit, miss & eviction of prepared statement cache.
+func TestStmtCache(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "cache.db")), StmtCacheSize(2))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	type testCase struct {
+		query string         // Query to execute
+		want  StmtCacheStats // Statistics after query
+	}
+
+	tests := []testCase{
+		{"SELECT val FROM test_table", StmtCacheStats{0, 1, 1}},
+		{"SELECT val FROM test_table", StmtCacheStats{1, 1, 1}},
+		{"SELECT content FROM test_table", StmtCacheStats{1, 2, 2}},
+		{"SELECT * FROM test_table", StmtCacheStats{1, 3, 2}},
+		// Evicted by least recently used
+		{"SELECT val FROM test_table", StmtCacheStats{1, 4, 2}},
+		{"SELECT * FROM test_table", StmtCacheStats{2, 4, 2}},
+	}
+
+	for idx, tt := range tests {
+		rows, err := db.Query(tt.query)
+		if !assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err) {
+			continue
+		}
+
+		// Rows should be usable after statement released
+		ct := 0
+		for rows.Next() {
+			ct++
+		}
+		rows.Close()
+
+		assert.EqualValuesf(t, 2, ct, "Case %d: Unexpected row count", idx)
+		assert.EqualValuesf(t, tt.want, db.StmtCacheStats(), "Case %d: Unexpected stats", idx)
+	}
+
+	// Cache is cleared when closed
+	err = db.Close()
+	assert.Nilf(t, err, "Unexpected error when close: %v", err)
+	assert.EqualValues(t, StmtCacheStats{}, db.StmtCacheStats())
+}
+
+// Ensure no statement is cached when cache is disabled.
+func TestStmtCacheDisabled(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "no_cache.db")), StmtCacheSize(0))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	for i := 0; i < 3; i++ {
+		row, err := db.QueryRow("SELECT val FROM test_table WHERE content = ?", "abc")
+		assert.Nilf(t, err, "Unexpected error: %v", err)
+
+		var val int
+		err = row.Scan(&val)
+		assert.Nilf(t, err, "Unexpected error when scan: %v", err)
+		assert.EqualValues(t, 123, val)
+	}
+
+	assert.EqualValues(t, StmtCacheStats{0, 3, 0}, db.StmtCacheStats())
+}
+
+// Ensure cached statements are safe for concurrent use, even evicted by others.
+func TestStmtCacheConcurrent(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "concurrent.db")), StmtCacheSize(1))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	queries := []string{
+		"SELECT val FROM test_table WHERE content = ?",
+		"SELECT val FROM test_table WHERE content = ? LIMIT 1",
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(query string) {
+			defer wg.Done()
+
+			var val int
+			row, err := db.QueryRow(query, "def")
+			if err == nil {
+				err = row.Scan(&val)
+			}
+			errs <- err
+		}(queries[i%len(queries)])
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.Nilf(t, err, "Unexpected error: %v", err)
+	}
+}
+
+// Ensure transaction only reuse cached statements, without counting miss for uncached statements.
+func TestStmtCacheTx(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "cache_tx.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	// Cache statement by LazyDB
+	row, err := db.QueryRow("SELECT val FROM test_table WHERE content = ?", "abc")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	row.Scan(new(int))
+	assert.EqualValues(t, StmtCacheStats{0, 1, 1}, db.StmtCacheStats())
+
+	err = db.WithTx(context.Background(), func(tx *Tx) error {
+		// Cached statement
+		row, err := tx.QueryRow("SELECT val FROM test_table WHERE content = ?", "def")
+		if err != nil {
+			return err
+		}
+		row.Scan(new(int))
+
+		// Not cached statement
+		_, err = tx.Exec("UPDATE test_table SET val = ? WHERE content = ?", 1, "abc")
+		return err
+	})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, StmtCacheStats{1, 1, 1}, db.StmtCacheStats())
+}
@@ -0,0 +1,173 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// Storage of database.
+type storageMode int
+
+const (
+	storageFile   storageMode = iota // Database file in DbPath, default
+	storageMemory                    // In-memory database, shared by all connections
+	storageTemp                      // Database file in temporary directory, removed when closed
+)
+
+func (s storageMode) apply(opts *databaseOpts) {
+	opts.Storage = s
+}
+
+// Use in-memory database, which is shared by all connections of LazyDB.
+//
+// Content of database is lost when LazyDB is closed. BackupTo() can be used to
+// snapshot database into file, and RestoreFrom() will copy backup into memory.
+// DbPath is only used to name backup files.
+func InMemory() DatabaseOption {
+	return storageMemory
+}
+
+// Use database file inside a new temporary directory, which is removed when LazyDB is closed.
+// Base name of DbPath is used as database filename.
+func TempFile() DatabaseOption {
+	return storageTemp
+}
+
+// ---------------------------------------------------
+type readOnly struct {
+	immutable bool
+}
+
+func (r readOnly) apply(opts *databaseOpts) {
+	opts.ReadOnly = true
+	opts.Immutable = r.immutable
+}
+
+// Open database file in read-only mode. The file will not be created if not exist.
+// It cannot be used with InMemory() or TempFile(), which Connect() will return ErrReadOnly.
+//
+// Exec(), ExecMultiple(), migrations & restore will return ErrReadOnly,
+// while queries & BackupTo() are still allowed. WithTx() is also allowed for
+// consistent reads across queries, which any write inside is rejected by SQLite.
+func ReadOnly() DatabaseOption {
+	return readOnly{}
+}
+
+// Open database file in read-only mode, same as ReadOnly(), and treat it as immutable.
+//
+// SQLite will skip all locking & change detection, so the file MUST NOT be modified
+// by any process while it is opened, e.g. a file on read-only media.
+func Immutable() DatabaseOption {
+	return readOnly{immutable: true}
+}
+
+// Escape characters that have special meaning in SQLite URI filename.
+var uriEscaper = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+
+// Get DSN to open database file in read-only mode.
+func readOnlyDsn(path string, immutable bool) string {
+	dsn := "file:" + uriEscaper.Replace(path) + "?mode=ro"
+	if immutable {
+		dsn += "&immutable=1"
+	}
+	return dsn
+}
+
+// Counter of in-memory databases, to give each database unique name.
+var memoryCount atomic.Uint64
+
+// Prepare storage of database, then get DSN to open database.
+// Value of created is true if in-memory database or temporary directory is created by this call.
+//
+// Storage is kept until released, so reconnecting after restore will use same storage.
+func (l *LazyDB) prepareStorage(ctx context.Context) (dsn string, created bool, err error) {
+	switch l.storage {
+	case storageMemory:
+		if l.keepalive == nil {
+			l.memoryDsn = fmt.Sprintf("file:lazydb-memory-%d?mode=memory&cache=shared", memoryCount.Add(1))
+
+			// In-memory database is deleted when last connection closed,
+			// keep a connection outside of pool, as pool may close idle connections
+			connector, err := newConnector(l.memoryDsn, nil)
+			if err != nil {
+				return "", false, err
+			}
+
+			l.keepalive, err = connector.Connect(ctx)
+			if err != nil {
+				return "", false, err
+			}
+			created = true
+		}
+		return l.memoryDsn, created, nil
+
+	case storageTemp:
+		if l.tempDir == "" {
+			dir, err := os.MkdirTemp("", "lazydb-*")
+			if err != nil {
+				return "", false, err
+			}
+			l.tempDir = dir
+			created = true
+		}
+	}
+
+	// Read-only database must exist, and never be created
+	if l.readOnly {
+		_, err = os.Stat(l.dbPath)
+		if err != nil {
+			return "", false, err
+		}
+		return readOnlyDsn(l.dbPath, l.immutable), false, nil
+	}
+
+	// Create Database if need
+	path := l.filePath()
+	err = createDbFile(path)
+	if err != nil {
+		return "", created, err
+	}
+	return path, created, nil
+}
+
+// Get path of database file, which is inside temporary directory for temporary database.
+func (l *LazyDB) filePath() string {
+	if l.tempDir != "" {
+		return filepath.Join(l.tempDir, filepath.Base(l.dbPath))
+	}
+	return l.dbPath
+}
+
+// Release in-memory database or temporary directory, if any.
+func (l *LazyDB) releaseStorage() error {
+	var keepaliveErr, tempErr error
+
+	if l.keepalive != nil {
+		keepaliveErr = l.keepalive.Close()
+		l.keepalive = nil
+	}
+
+	if l.tempDir != "" {
+		tempErr = os.RemoveAll(l.tempDir)
+		l.tempDir = ""
+	}
+
+	return errors.Join(keepaliveErr, tempErr)
+}
+
+// Restore in-memory database by copying backup into it, as there is no file to replace.
+func (l *LazyDB) restoreMemory(ctx context.Context, path string) error {
+	src, err := sql.Open(DatabaseType, path+"?_query_only=1")
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return copyDatabase(ctx, l.db, src, l.backupProgress)
+}
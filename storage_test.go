@@ -0,0 +1,216 @@
+package lazydb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemory(t *testing.T) {
+	db := New(
+		InMemory(),
+		Migrate(fsNormalTestV2, dirNormalTestV2),
+		Pool(PoolConfig{MaxOpenConns: 2, ConnMaxIdleTime: time.Nanosecond}),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+
+	// Migration
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	// Wrappers
+	createDummyTable(db.DB())
+	_, err = db.Exec("INSERT INTO test_table (content, val) VALUES (?, ?)", "memory", 1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 3, countTestTable(t, db))
+
+	// Database is shared by all connections, and not removed when idle connections closed
+	var ct int
+	row, err := db.QueryRow("SELECT COUNT(*) FROM test_table")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.Nilf(t, row.Scan(&ct), "Unexpected error when scan")
+	assert.EqualValues(t, 3, ct)
+
+	// No file is created
+	assert.Falsef(t, IsFileExist(Path), "Database file should not be created")
+
+	// Snapshot to disk, then restore
+	dest := filepath.Join(t.TempDir(), "snapshot.db")
+	err = db.BackupTo(dest)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	_, err = db.Exec("DELETE FROM test_table")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	err = db.RestoreFrom(dest)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 3, countTestTable(t, db))
+
+	// Other in-memory database is isolated
+	other := New(InMemory())
+	err = other.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer other.Close()
+
+	_, err = other.Query("SELECT * FROM test_table")
+	assert.NotNilf(t, err, "Table should not exist in other database")
+}
+
+func TestTempFile(t *testing.T) {
+	db := New(
+		TempFile(),
+		DbPath("cache.db"),
+		Migrate(fsNormalTestV2, dirNormalTestV2),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+
+	path := db.filePath()
+	assert.EqualValues(t, "cache.db", filepath.Base(path))
+	assert.NotEqualValues(t, "cache.db", path)
+	assert.Truef(t, IsFileExist(path), "Database file should be created")
+
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	createDummyTable(db.DB())
+
+	// Temporary file is kept when restore
+	dest := filepath.Join(t.TempDir(), "snapshot.db")
+	err = db.BackupTo(dest)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	err = db.RestoreFrom(dest)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, path, db.filePath())
+	assert.EqualValues(t, 2, countTestTable(t, db))
+
+	// Temporary directory is removed when closed
+	err = db.Close()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.Falsef(t, IsFileExist(path), "Database file should be removed")
+	assert.Falsef(t, IsFileExist(filepath.Dir(path)), "Temporary directory should be removed")
+
+	// Database path of option is kept
+	assert.EqualValues(t, "cache.db", db.dbPath)
+}
+
+// Ensure storage is released when connect failed.
+func TestStorageConnectFailed(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	for idx, opt := range []DatabaseOption{InMemory(), TempFile()} {
+		db := New(opt, JournalMode("UNKNOWN"))
+
+		err := db.Connect()
+		assert.ErrorIsf(t, err, ErrPragmaNotApplied, "Case %d: Unexpected error: %v", idx, err)
+		assert.Nilf(t, db.keepalive, "Case %d: In-memory database should be released", idx)
+		assert.EqualValuesf(t, "", db.tempDir, "Case %d: Temporary directory should be released", idx)
+
+		entries, _ := os.ReadDir(tmpDir)
+		assert.Emptyf(t, entries, "Case %d: Temporary directory should be removed", idx)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "readonly.db")
+
+	// Prepare database with data
+	origin := New(DbPath(path), Migrate(fsNormalTestV2, dirNormalTestV2))
+	err := origin.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	_, err = origin.Migrate()
+	if err != nil {
+		t.Fatal("Failed to migrate: ", err)
+	}
+	createDummyTable(origin.DB())
+	origin.Close()
+
+	for idx, opt := range []DatabaseOption{ReadOnly(), Immutable()} {
+		db := New(DbPath(path), Migrate(fsNormalTestV2, dirNormalTestV2), opt)
+		err := db.Connect()
+		if err != nil {
+			t.Fatalf("Case %d: Failed to connect: %v", idx, err)
+		}
+
+		// Queries are allowed
+		assert.EqualValuesf(t, 2, countTestTable(t, db), "Case %d: Unexpected count", idx)
+
+		// Modification is rejected
+		_, err = db.Exec("DELETE FROM test_table")
+		assert.ErrorIsf(t, err, ErrReadOnly, "Case %d: Unexpected error: %v", idx, err)
+
+		_, err = db.ExecMultiple([]ParamQuery{Param("DELETE FROM test_table")})
+		assert.ErrorIsf(t, err, ErrReadOnly, "Case %d: Unexpected error: %v", idx, err)
+
+		_, err = db.Migrate()
+		assert.ErrorIsf(t, err, ErrReadOnly, "Case %d: Unexpected error: %v", idx, err)
+
+		_, err = db.DB().Exec("DELETE FROM test_table")
+		assert.NotNilf(t, err, "Case %d: Database should be opened in read-only mode", idx)
+
+		// Transaction is allowed for reading only
+		err = db.WithTx(context.Background(), func(tx *Tx) error {
+			row, err := tx.QueryRow("SELECT COUNT(*) FROM test_table")
+			if err != nil {
+				return err
+			}
+			row.Scan(new(int))
+
+			_, err = tx.Exec("DELETE FROM test_table")
+			return err
+		})
+		assert.NotNilf(t, err, "Case %d: Write inside transaction should be rejected", idx)
+		assert.EqualValuesf(t, 2, countTestTable(t, db), "Case %d: Unexpected count", idx)
+
+		// Backup is allowed, but restore is rejected
+		dest := filepath.Join(tmpDir, "backup.db")
+		err = db.BackupTo(dest)
+		assert.Nilf(t, err, "Case %d: Unexpected error: %v", idx, err)
+
+		err = db.RestoreFrom(dest)
+		assert.ErrorIsf(t, err, ErrReadOnly, "Case %d: Unexpected error: %v", idx, err)
+
+		db.Close()
+		os.Remove(dest)
+	}
+}
+
+// Ensure read-only mode cannot be used with newly created database.
+func TestReadOnlyInvalidStorage(t *testing.T) {
+	for idx, opt := range []DatabaseOption{InMemory(), TempFile()} {
+		db := New(opt, ReadOnly())
+
+		err := db.Connect()
+		assert.ErrorIsf(t, err, ErrReadOnly, "Case %d: Unexpected error: %v", idx, err)
+		assert.Falsef(t, db.Connected(), "Case %d: Database should not be connected", idx)
+		assert.Nilf(t, db.keepalive, "Case %d: In-memory database should not be created", idx)
+		assert.EqualValuesf(t, "", db.tempDir, "Case %d: Temporary directory should not be created", idx)
+	}
+}
+
+// Ensure read-only database is not created when not exist.
+func TestReadOnlyNotExist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "readonly.db")
+
+	db := New(DbPath(path), ReadOnly())
+	err := db.Connect()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.False(t, db.Connected())
+	assert.Falsef(t, IsFileExist(filepath.Dir(path)), "Directory should not be created")
+}
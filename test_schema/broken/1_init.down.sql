@@ -0,0 +1 @@
+PRAGMA user_version = 0
\ No newline at end of file
@@ -0,0 +1 @@
+PRAGMA user_version = 1
\ No newline at end of file
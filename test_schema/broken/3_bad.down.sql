@@ -0,0 +1 @@
+PRAGMA user_version = 2
\ No newline at end of file
@@ -0,0 +1,2 @@
+PRAGMA user_version = 3;
+CREATE TABLE broken (;
\ No newline at end of file
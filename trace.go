@@ -0,0 +1,172 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Operation that execute a statement.
+type QueryOp string
+
+const (
+	QueryOpExec         QueryOp = "exec"          // Statement of Exec()
+	QueryOpExecMultiple QueryOp = "exec_multiple" // Each statement of ExecMultiple()
+	QueryOpQuery        QueryOp = "query"         // Statement of Query()
+	QueryOpQueryRow     QueryOp = "query_row"     // Statement of QueryRow()
+	QueryOpMigration    QueryOp = "migration"     // Script of each migration version
+)
+
+// Value that replace redacted arguments.
+const redactedValue = "[REDACTED]"
+
+// Information of executed statement that passed to tracer.
+type QueryEvent struct {
+	Op           QueryOp       // Operation that execute the statement
+	Query        string        // Statement with placeholders
+	Args         []any         // Arguments of statement, with sensitive values redacted
+	Filled       string        // Statement filled with arguments, same as Query if cannot be filled
+	Duration     time.Duration // Time used to execute statement
+	RowsAffected int64         // Rows affected by statement, -1 if not available
+	Err          error         // Error returned by statement, nil if success
+}
+
+// Tracer that receive every statement executed by LazyDB.
+type queryTracer struct {
+	fn     func(ctx context.Context, e QueryEvent) // Function to receive event
+	redact map[string]bool                         // Lower-cased column names to redact
+}
+
+// Create tracer by given options, nil if no tracer is set.
+func newQueryTracer(fn func(ctx context.Context, e QueryEvent), columns []string) *queryTracer {
+	if fn == nil {
+		return nil
+	}
+
+	redact := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		redact[strings.ToLower(col)] = true
+	}
+
+	return &queryTracer{fn: fn, redact: redact}
+}
+
+// Send event of executed statement to tracer. No effect if tracer is nil.
+//
+// Result can be nil if rows affected is not available.
+func (t *queryTracer) trace(ctx context.Context, op QueryOp, query string, args []any, start time.Time, result sql.Result, err error) {
+	if t == nil {
+		return
+	}
+
+	event := QueryEvent{
+		Op:           op,
+		Query:        query,
+		Args:         t.redactArgs(query, expandArgs(args)),
+		Duration:     time.Since(start),
+		RowsAffected: -1,
+		Err:          err,
+	}
+
+	filled, fillErr := asFilledQuery(query, event.Args...)
+	if fillErr != nil {
+		filled = query
+	}
+	event.Filled = filled
+
+	if result != nil {
+		if n, err := result.RowsAffected(); err == nil {
+			event.RowsAffected = n
+		}
+	}
+
+	t.fn(ctx, event)
+}
+
+// Pattern of comparison that end with placeholder, e.g. "col = ?".
+var comparePattern = regexp.MustCompile(`(?i)(\w+)["'\x60\]]?\s*(?:==?|!=|<>|<=|>=|<|>|\bLIKE|\bGLOB|\bIS(?:\s+NOT)?)\s*$`)
+
+// Pattern of insert statement with column list, e.g. "INSERT INTO t (a, b) VALUES (".
+var insertPattern = regexp.MustCompile(`(?is)\bINSERT\b.*?\bINTO\s+\S+\s*\(([^)]*)\)\s*VALUES\s*\(`)
+
+// Get copy of args with values of sensitive columns replaced.
+//
+// Column of positional argument is detected by comparison before placeholder,
+// or column list of insert statement. Named argument is detected by its name.
+func (t *queryTracer) redactArgs(query string, args []any) []any {
+	if len(t.redact) == 0 || len(args) == 0 {
+		return args
+	}
+
+	// Find placeholder indexes & names that belong to sensitive columns
+	sensitive := make(map[int]bool)
+	sensitiveNames := make(map[string]bool)
+	var insertCols []string
+	if m := insertPattern.FindStringSubmatch(query); m != nil {
+		insertCols = strings.Split(m[1], ",")
+	}
+
+	prev := ""
+	placeholders := 0
+	for _, tk := range tokenizeQuery(query) {
+		if tk.index == 0 {
+			prev = tk.text
+			continue
+		}
+
+		col := tk.name
+		if m := comparePattern.FindStringSubmatch(prev); m != nil {
+			col = m[1]
+		} else if len(insertCols) > 0 && col == "" {
+			col = strings.Trim(strings.TrimSpace(insertCols[placeholders%len(insertCols)]), "\"'`[]")
+		}
+
+		if t.redact[strings.ToLower(col)] || t.redact[strings.ToLower(tk.name)] {
+			sensitive[tk.index] = true
+			sensitiveNames[tk.name] = true
+		}
+		placeholders++
+	}
+
+	redacted := make([]any, len(args))
+	for i, item := range args {
+		redacted[i] = item
+
+		if arg, ok := item.(sql.NamedArg); ok {
+			if t.redact[strings.ToLower(arg.Name)] || sensitiveNames[arg.Name] {
+				redacted[i] = sql.Named(arg.Name, redactedValue)
+			}
+			continue
+		}
+
+		if sensitive[i+1] {
+			redacted[i] = redactedValue
+		}
+	}
+
+	return redacted
+}
+
+// Create tracer function that log every statement by given logger.
+// Statement with error is logged as error level, otherwise debug level.
+func slogTracer(logger *slog.Logger) func(ctx context.Context, e QueryEvent) {
+	return func(ctx context.Context, e QueryEvent) {
+		level := slog.LevelDebug
+		attrs := []slog.Attr{
+			slog.String("op", string(e.Op)),
+			slog.String("sql", e.Filled),
+			slog.Duration("duration", e.Duration),
+			slog.Int64("rows_affected", e.RowsAffected),
+		}
+
+		if e.Err != nil {
+			level = slog.LevelError
+			attrs = append(attrs, slog.Any("error", e.Err))
+		}
+
+		logger.LogAttrs(ctx, level, "lazydb query", attrs...)
+	}
+}
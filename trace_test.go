@@ -0,0 +1,140 @@
+package lazydb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Create tracer function that record all events into given slice.
+func recordTracer(events *[]QueryEvent) func(ctx context.Context, e QueryEvent) {
+	return func(ctx context.Context, e QueryEvent) {
+		*events = append(*events, e)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	var events []QueryEvent
+
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "trace.db")),
+		Migrate(fsNormalTestV2, dirNormalTestV2),
+		Tracer(recordTracer(&events)),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+
+	// Migration scripts
+	_, err = db.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.Lenf(t, events, 2, "Each migration version should be traced")
+	for idx, e := range events {
+		assert.EqualValuesf(t, QueryOpMigration, e.Op, "Case %d: Unexpected op", idx)
+		assert.Nilf(t, e.Err, "Case %d: Unexpected error: %v", idx, e.Err)
+	}
+
+	createDummyTable(db.DB())
+	events = nil
+
+	// Wrappers
+	db.Exec("UPDATE test_table SET val = ? WHERE content = ?", 1, "abc")
+	db.ExecMultiple([]ParamQuery{Param("DELETE FROM test_table WHERE val > ?", 100)})
+	rows, _ := db.Query("SELECT * FROM test_table WHERE val = ?", 1)
+	rows.Close()
+	row, _ := db.QueryRow("SELECT val FROM test_table WHERE content = :c", sql.Named("c", "it's"))
+	row.Scan(new(int))
+	db.Exec("SELECT * FROM unknown_table")
+
+	type testCase struct {
+		op           QueryOp
+		filled       string
+		rowsAffected int64
+		hasErr       bool
+	}
+
+	tests := []testCase{
+		{QueryOpExec, `UPDATE test_table SET val = 1 WHERE content = 'abc'`, 1, false},
+		{QueryOpExecMultiple, `DELETE FROM test_table WHERE val > 100`, 1, false},
+		{QueryOpQuery, `SELECT * FROM test_table WHERE val = 1`, -1, false},
+		{QueryOpQueryRow, `SELECT val FROM test_table WHERE content = 'it''s'`, -1, false},
+		{QueryOpExec, `SELECT * FROM unknown_table`, -1, true},
+	}
+
+	if !assert.Len(t, events, len(tests)) {
+		return
+	}
+
+	for idx, tt := range tests {
+		e := events[idx]
+		assert.EqualValuesf(t, tt.op, e.Op, "Case %d: Unexpected op", idx)
+		assert.EqualValuesf(t, tt.filled, e.Filled, "Case %d: Unexpected filled", idx)
+		assert.EqualValuesf(t, tt.rowsAffected, e.RowsAffected, "Case %d: Unexpected rows affected", idx)
+		assert.EqualValuesf(t, tt.hasErr, e.Err != nil, "Case %d: Unexpected error: %v", idx, e.Err)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	tracer := newQueryTracer(func(ctx context.Context, e QueryEvent) {}, []string{"Password", "token"})
+
+	type testCase struct {
+		query string
+		args  []any
+		want  []any
+	}
+
+	tests := []testCase{
+		// Comparison
+		{`SELECT * FROM users WHERE name = ? AND password = ?`, []any{"a", "b"}, []any{"a", redactedValue}},
+		{`SELECT * FROM users WHERE "PASSWORD"<>? OR token LIKE ?`, []any{"a", "b"}, []any{redactedValue, redactedValue}},
+
+		// Insert column list
+		{`INSERT INTO users (name, password) VALUES (?, ?), (?, ?)`, []any{"a", "b", "c", "d"}, []any{"a", redactedValue, "c", redactedValue}},
+
+		// Named
+		{`UPDATE users SET password = :pw WHERE name = :token`, []any{sql.Named("pw", "a"), sql.Named("token", "b")},
+			[]any{sql.Named("pw", redactedValue), sql.Named("token", redactedValue)}},
+
+		// Nothing to redact
+		{`SELECT * FROM users WHERE name = ?`, []any{"a"}, []any{"a"}},
+	}
+
+	for idx, tt := range tests {
+		result := tracer.redactArgs(tt.query, tt.args)
+		assert.EqualValuesf(t, tt.want, result, "Case %d: Unexpected redacted args", idx)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	db := New(
+		DbPath(filepath.Join(t.TempDir(), "logger.db")),
+		Logger(logger),
+		RedactColumns("content"),
+	)
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	_, err = db.Exec("INSERT INTO test_table (content, val) VALUES (?, ?)", "secret", 1)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	output := buf.String()
+	assert.Contains(t, output, "level=DEBUG")
+	assert.Contains(t, output, "op=exec")
+	assert.Contains(t, output, "rows_affected=1")
+	assert.Contains(t, output, redactedValue)
+	assert.NotContains(t, output, "secret")
+}
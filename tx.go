@@ -0,0 +1,262 @@
+package lazydb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Transaction created by WithTx(), with same wrapper functions as LazyDB.
+//
+// Tx is only valid inside the function passed to WithTx(),
+// and MUST NOT be used after that function returned.
+type Tx struct {
+	tx     *sql.Tx         // Transaction of database
+	owner  *LazyDB         // LazyDB that begin transaction
+	ctx    context.Context // Context that carry this transaction
+	stmts  *stmtCache      // Cache of prepared statements of LazyDB
+	tracer *queryTracer    // Tracer of LazyDB, can be nil
+	depth  int             // Number of savepoints, 0 for outermost transaction
+}
+
+// Key of context value that carry active transaction.
+type txKey struct{}
+
+// Create transaction that carried by given context.
+func newTx(ctx context.Context, sqlTx *sql.Tx, owner *LazyDB, depth int) *Tx {
+	tx := &Tx{tx: sqlTx, owner: owner, stmts: owner.stmts, tracer: owner.tracer, depth: depth}
+	tx.ctx = context.WithValue(ctx, txKey{}, tx)
+	return tx
+}
+
+// Get active transaction of LazyDB that carried by context, nil if not any.
+func (l *LazyDB) activeTx(ctx context.Context) *Tx {
+	tx, _ := ctx.Value(txKey{}).(*Tx)
+	if tx == nil || tx.owner != l {
+		return nil
+	}
+	return tx
+}
+
+// Run given function inside a transaction.
+//
+// The transaction is committed when function return nil,
+// otherwise it will be rollback, include function panic.
+// Panic will be re-thrown after rollback.
+//
+// Writes by Exec(), ExecMultiple() & WithTx() of LazyDB wait until transaction finished,
+// unless they are called with context from Tx.Context(), which run inside transaction instead.
+// Nested WithTx() will become a savepoint, same as Tx.WithTx().
+//
+// For database opened by ReadOnly(), transaction is allowed for consistent reads,
+// but any write inside will be rejected by SQLite.
+func (l *LazyDB) WithTx(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	if l.db == nil {
+		return ErrNilDatabase
+	}
+
+	// Called inside transaction, use savepoint instead
+	if tx := l.activeTx(ctx); tx != nil {
+		return tx.WithTx(ctx, fn)
+	}
+
+	unlock, err := l.lockWriter(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	sqlTx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	tx := newTx(ctx, sqlTx, l, 0)
+
+	// Rollback when panic
+	defer func() {
+		if p := recover(); p != nil {
+			sqlTx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		return rollbackErr(err, sqlTx.Rollback())
+	}
+
+	return sqlTx.Commit()
+}
+
+// Run given function inside a nested transaction, by SQLite SAVEPOINT.
+//
+// The savepoint is released when function return nil,
+// otherwise it will be rollback without affecting outer transaction.
+// Panic will be re-thrown after rollback.
+func (t *Tx) WithTx(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	name := fmt.Sprintf("lazydb_sp_%d", t.depth+1)
+
+	_, err = t.tx.ExecContext(ctx, "SAVEPOINT "+name)
+	if err != nil {
+		return err
+	}
+
+	nested := newTx(ctx, t.tx, t.owner, t.depth+1)
+
+	// Rollback to savepoint when panic
+	defer func() {
+		if p := recover(); p != nil {
+			nested.rollbackTo(ctx, name)
+			panic(p)
+		}
+	}()
+
+	err = fn(nested)
+	if err != nil {
+		return rollbackErr(err, nested.rollbackTo(ctx, name))
+	}
+
+	_, err = t.tx.ExecContext(ctx, "RELEASE "+name)
+	return err
+}
+
+// Rollback changes after savepoint with given name, then remove the savepoint.
+func (t *Tx) rollbackTo(ctx context.Context, name string) error {
+	_, err := t.tx.ExecContext(ctx, "ROLLBACK TO "+name)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.tx.ExecContext(ctx, "RELEASE "+name)
+	return err
+}
+
+// Combine error that cause rollback, with error returned by rollback if any.
+func rollbackErr(err, rbErr error) error {
+	if rbErr == nil {
+		return err
+	}
+	return errors.Join(err, fmt.Errorf("failed to rollback: %w", rbErr))
+}
+
+// Get *sql.Tx of transaction.
+func (t *Tx) Tx() *sql.Tx {
+	return t.tx
+}
+
+// Get context that carry this transaction.
+//
+// Wrapper functions & WithTx() of LazyDB called with this context will run inside transaction,
+// e.g. helper functions that only accept LazyDB.
+func (t *Tx) Context() context.Context {
+	return t.ctx
+}
+
+// Get prepared statement of query that bound to transaction.
+//
+// Cached statement is reused if any. Otherwise statement is prepared on connection of transaction
+// without caching, as the pool may have no other connection available, e.g. writer pool of split-pool mode.
+// Statement is closed automatically when transaction committed or rollback.
+func (t *Tx) stmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	if t.stmts == nil {
+		return t.tx.PrepareContext(ctx, query)
+	}
+
+	cs, ok := t.stmts.lookup(query)
+	if !ok {
+		return t.tx.PrepareContext(ctx, query)
+	}
+	defer t.stmts.release(cs)
+
+	return t.tx.StmtContext(ctx, cs.stmt), nil
+}
+
+// Execute given query inside transaction, by cached prepared statement.
+func (t *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return t.ExecContext(context.Background(), query, args...)
+}
+
+// Execute given queries inside a savepoint, which any failure will cause a rollback to savepoint.
+func (t *Tx) execMultiple(ctx context.Context, pQueries []ParamQuery) ([]sql.Result, error) {
+	results := make([]sql.Result, 0)
+
+	err := t.WithTx(ctx, func(tx *Tx) error {
+		for _, query := range pQueries {
+			result, err := tx.ExecContext(ctx, query.Query, query.Args...)
+			if err != nil {
+				return fmt.Errorf("failed to exec '%s' with (%v): %w", query.Query, query.Args, err)
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Execute given query with context inside transaction, by cached prepared statement.
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	// Expand map of named parameters
+	args = expandArgs(args)
+
+	start := time.Now()
+	stmt, err := t.stmt(ctx, query)
+	if err != nil {
+		t.tracer.trace(ctx, QueryOpExec, query, args, start, nil, err)
+		return nil, err
+	}
+
+	result, err := stmt.ExecContext(ctx, args...)
+	t.tracer.trace(ctx, QueryOpExec, query, args, start, result, err)
+	return result, err
+}
+
+// Wrapper for Query() function inside transaction, using prepared statement.
+func (t *Tx) Query(query string, args ...any) (*sql.Rows, error) {
+	return t.QueryContext(context.Background(), query, args...)
+}
+
+// Wrapper for QueryContext() function inside transaction, using prepared statement.
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	// Expand map of named parameters
+	args = expandArgs(args)
+
+	start := time.Now()
+	stmt, err := t.stmt(ctx, query)
+	if err != nil {
+		t.tracer.trace(ctx, QueryOpQuery, query, args, start, nil, err)
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	t.tracer.trace(ctx, QueryOpQuery, query, args, start, nil, err)
+	return rows, err
+}
+
+// Wrapper for QueryRow() function inside transaction, using prepared statement.
+func (t *Tx) QueryRow(query string, args ...any) (*sql.Row, error) {
+	return t.QueryRowContext(context.Background(), query, args...)
+}
+
+// Wrapper for QueryRowContext() function inside transaction, using prepared statement.
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...any) (*sql.Row, error) {
+	// Expand map of named parameters
+	args = expandArgs(args)
+
+	start := time.Now()
+	stmt, err := t.stmt(ctx, query)
+	if err != nil {
+		t.tracer.trace(ctx, QueryOpQueryRow, query, args, start, nil, err)
+		return nil, err
+	}
+
+	row := stmt.QueryRowContext(ctx, args...)
+	t.tracer.trace(ctx, QueryOpQueryRow, query, args, start, nil, row.Err())
+	return row, nil
+}
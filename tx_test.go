@@ -0,0 +1,189 @@
+package lazydb
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTx(t *testing.T) {
+	errTest := errors.New("test error")
+
+	type testCase struct {
+		fn      func(tx *Tx) error // Function run in transaction
+		wantErr error              // Expected error
+		wantCt  int                // Row count after transaction
+	}
+
+	insert := func(tx *Tx) error {
+		_, err := tx.Exec("INSERT INTO test_table (content, val) VALUES (?, ?)", "ghi", 789)
+		return err
+	}
+
+	tests := []testCase{
+		// Commit
+		{insert, nil, 3},
+		// Rollback by error
+		{func(tx *Tx) error {
+			insert(tx)
+			return errTest
+		}, errTest, 2},
+		// Read-modify-write
+		{func(tx *Tx) error {
+			row, err := tx.QueryRow("SELECT val FROM test_table WHERE content = ?", "abc")
+			if err != nil {
+				return err
+			}
+
+			var val int
+			err = row.Scan(&val)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec("UPDATE test_table SET val = ? WHERE content = ?", val+1, "abc")
+			return err
+		}, nil, 2},
+		// Nested savepoint released
+		{func(tx *Tx) error {
+			return tx.WithTx(context.Background(), insert)
+		}, nil, 3},
+		// Nested savepoint rollback, outer committed
+		{func(tx *Tx) error {
+			insert(tx)
+			err := tx.WithTx(context.Background(), func(tx *Tx) error {
+				insert(tx)
+				return errTest
+			})
+			assert.ErrorIs(t, err, errTest)
+			return nil
+		}, nil, 3},
+		// Nested savepoint released, outer rollback
+		{func(tx *Tx) error {
+			tx.WithTx(context.Background(), func(tx *Tx) error {
+				return tx.WithTx(context.Background(), insert)
+			})
+			return errTest
+		}, errTest, 2},
+	}
+
+	for idx, tt := range tests {
+		db := New(DbPath(filepath.Join(t.TempDir(), "tx.db")))
+		err := db.Connect()
+		if err != nil {
+			t.Fatal("Failed to connect: ", err)
+		}
+		createDummyTable(db.DB())
+
+		err = db.WithTx(context.Background(), tt.fn)
+		assert.ErrorIsf(t, err, tt.wantErr, "Case %d: Unexpected error: %v", idx, err)
+		assert.EqualValuesf(t, tt.wantCt, countTestTable(t, db), "Case %d: Unexpected row count", idx)
+
+		db.Close()
+	}
+}
+
+// Ensure transaction is rollback when function panic.
+func TestWithTxPanic(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "tx_panic.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	assert.Panics(t, func() {
+		db.WithTx(context.Background(), func(tx *Tx) error {
+			tx.Exec("DELETE FROM test_table")
+			panic("test panic")
+		})
+	})
+	assert.EqualValues(t, 2, countTestTable(t, db), "Rows should not be deleted after panic")
+
+	// Panic in nested transaction, outer transaction still usable
+	err = db.WithTx(context.Background(), func(tx *Tx) error {
+		assert.Panics(t, func() {
+			tx.WithTx(context.Background(), func(tx *Tx) error {
+				tx.Exec("DELETE FROM test_table")
+				panic("test panic")
+			})
+		})
+		return nil
+	})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 2, countTestTable(t, db), "Rows should not be deleted after nested panic")
+}
+
+func TestWithTxNilDb(t *testing.T) {
+	db := &LazyDB{db: nil}
+
+	err := db.WithTx(context.Background(), func(tx *Tx) error { return nil })
+	assert.ErrorIs(t, err, ErrNilDatabase)
+}
+
+// Ensure WithTx() of LazyDB inside transaction become savepoint, instead of waiting for transaction.
+func TestWithTxNestedLazyDB(t *testing.T) {
+	errTest := errors.New("test error")
+
+	db := New(DbPath(filepath.Join(t.TempDir(), "tx_nested.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	// Helper that only accept LazyDB
+	insert := func(ctx context.Context) error {
+		return db.WithTx(ctx, func(tx *Tx) error {
+			_, err := db.ExecContext(tx.Context(), "INSERT INTO test_table (content, val) VALUES (?, ?)", "ghi", 789)
+			return err
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = db.WithTx(ctx, func(tx *Tx) error {
+		err := insert(tx.Context())
+		if err != nil {
+			return err
+		}
+
+		// Nested rollback without affecting outer transaction
+		err = db.WithTx(tx.Context(), func(tx *Tx) error {
+			_, err := db.ExecMultipleContext(tx.Context(), []ParamQuery{Param("DELETE FROM test_table")})
+			if err != nil {
+				return err
+			}
+			return errTest
+		})
+		assert.ErrorIs(t, err, errTest)
+
+		// Uncommitted rows are visible inside transaction
+		row, err := db.QueryRowContext(tx.Context(), "SELECT COUNT(*) FROM test_table")
+		if err != nil {
+			return err
+		}
+
+		var ct int
+		err = row.Scan(&ct)
+		assert.EqualValues(t, 3, ct)
+		return err
+	})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 3, countTestTable(t, db))
+
+	// Outer rollback discard nested changes
+	err = db.WithTx(ctx, func(tx *Tx) error {
+		insert(tx.Context())
+		return errTest
+	})
+	assert.ErrorIs(t, err, errTest)
+	assert.EqualValues(t, 3, countTestTable(t, db))
+}
@@ -0,0 +1,229 @@
+package lazydb
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Kind of problem found in migration directory.
+type ProblemKind string
+
+const (
+	ProblemGap         ProblemKind = "gap"          // Version is missing between two versions
+	ProblemDuplicate   ProblemKind = "duplicate"    // More than one script for same version & direction
+	ProblemMissingUp   ProblemKind = "missing_up"   // Version has down script only
+	ProblemMissingDown ProblemKind = "missing_down" // Version has up script only
+	ProblemStrayFile   ProblemKind = "stray_file"   // File that not follow migration naming
+	ProblemEmptyScript ProblemKind = "empty_script" // Script that contains whitespace only
+	ProblemNestedDir   ProblemKind = "nested_dir"   // Directory inside migration directory
+)
+
+// Problem found in migration directory.
+type MigrationProblem struct {
+	Kind    ProblemKind // Kind of problem
+	Version uint        // Related version, 0 if not related to any version
+	File    string      // Related filename, empty if not related to any file
+	Message string      // Human readable description
+}
+
+func (p MigrationProblem) String() string {
+	return fmt.Sprintf("[%s] %s", p.Kind, p.Message)
+}
+
+// Error when migration directory contains any problem.
+type ValidationError struct {
+	Problems []MigrationProblem
+}
+
+func (e *ValidationError) Error() string {
+	msg := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msg = append(msg, p.String())
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidMigration, strings.Join(msg, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidMigration
+}
+
+// Check migration scripts inside given directory of fs.FS,
+// and report all problems found, ordered by version.
+//
+// Empty slice will be returned if no problem found.
+// Error will only be returned when directory cannot be read.
+func ValidateMigrations(fileSys fs.FS, dir string) ([]MigrationProblem, error) {
+	entries, err := fs.ReadDir(fileSys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := make([]MigrationProblem, 0)
+	scripts := make(map[uint]map[source.Direction][]string)
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		// Nested directory is not supported
+		if entry.IsDir() {
+			problems = append(problems, MigrationProblem{
+				Kind:    ProblemNestedDir,
+				File:    name,
+				Message: fmt.Sprintf("nested directory %s", name),
+			})
+			continue
+		}
+
+		// File that not follow naming
+		m, err := source.DefaultParse(name)
+		if err != nil {
+			problems = append(problems, MigrationProblem{
+				Kind:    ProblemStrayFile,
+				File:    name,
+				Message: fmt.Sprintf("file %s is not a migration script", name),
+			})
+			continue
+		}
+
+		// Script without any statement
+		content, err := fs.ReadFile(fileSys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.TrimSpace(string(content)) == "" {
+			problems = append(problems, MigrationProblem{
+				Kind:    ProblemEmptyScript,
+				Version: m.Version,
+				File:    name,
+				Message: fmt.Sprintf("script %s is empty", name),
+			})
+		}
+
+		// Record script by version & direction
+		if scripts[m.Version] == nil {
+			scripts[m.Version] = make(map[source.Direction][]string)
+		}
+		scripts[m.Version][m.Direction] = append(scripts[m.Version][m.Direction], name)
+	}
+
+	// Get sorted versions
+	versions := make([]uint, 0, len(scripts))
+	for v := range scripts {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	// Gaps between versions
+	problems = append(problems, gapProblems(versions)...)
+
+	for _, v := range versions {
+		// Pair of up & down script
+		up, down := scripts[v][source.Up], scripts[v][source.Down]
+
+		if len(up) == 0 {
+			problems = append(problems, MigrationProblem{
+				Kind:    ProblemMissingUp,
+				Version: v,
+				Message: fmt.Sprintf("version %d has no up script", v),
+			})
+		}
+
+		if len(down) == 0 {
+			problems = append(problems, MigrationProblem{
+				Kind:    ProblemMissingDown,
+				Version: v,
+				Message: fmt.Sprintf("version %d has no down script", v),
+			})
+		}
+
+		// Duplicate scripts
+		for _, files := range [][]string{up, down} {
+			if len(files) <= 1 {
+				continue
+			}
+
+			problems = append(problems, MigrationProblem{
+				Kind:    ProblemDuplicate,
+				Version: v,
+				File:    files[1],
+				Message: fmt.Sprintf("version %d has duplicate scripts: %s", v, strings.Join(files, ", ")),
+			})
+		}
+	}
+
+	// Problems without version first, then order by version
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Version < problems[j].Version
+	})
+
+	return problems, nil
+}
+
+// Get problems of missing versions between given versions, which must be in ascending order.
+func gapProblems(versions []uint) []MigrationProblem {
+	problems := make([]MigrationProblem, 0)
+
+	for idx := 1; idx < len(versions); idx++ {
+		prev, v := versions[idx-1], versions[idx]
+		if v <= prev+1 {
+			continue
+		}
+
+		msg := fmt.Sprintf("version %d is missing", prev+1)
+		if v-prev > 2 {
+			msg = fmt.Sprintf("version %d to %d are missing", prev+1, v-1)
+		}
+
+		problems = append(problems, MigrationProblem{
+			Kind:    ProblemGap,
+			Version: prev + 1,
+			Message: msg,
+		})
+	}
+
+	return problems
+}
+
+// Validate migration directory of LazyDB, when strict migration is enabled.
+//
+// Versions that defined by Go migrations will not be considered as gaps.
+func (l *LazyDB) validateMigrations() error {
+	if !l.strictMigrations {
+		return nil
+	}
+
+	problems, err := ValidateMigrations(l.migrateFs, l.migrateDir)
+	if err != nil {
+		return err
+	}
+
+	// Recalculate gaps with Go migrations
+	if len(l.goMigrations) > 0 {
+		migrations, err := l.migrations()
+		if err != nil {
+			return err
+		}
+
+		versions := make([]uint, 0, len(migrations))
+		for _, m := range migrations {
+			versions = append(versions, m.Version)
+		}
+
+		problems = slices.DeleteFunc(problems, func(p MigrationProblem) bool {
+			return p.Kind == ProblemGap
+		})
+		problems = append(problems, gapProblems(versions)...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
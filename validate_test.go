@@ -0,0 +1,106 @@
+package lazydb
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Get kinds of given problems.
+func problemKinds(problems []MigrationProblem) []ProblemKind {
+	result := make([]ProblemKind, 0)
+	for _, p := range problems {
+		result = append(result, p.Kind)
+	}
+	return result
+}
+
+func TestValidateMigrations(t *testing.T) {
+	type testCase struct {
+		fs      fs.FS
+		dir     string
+		want    []ProblemKind
+		wantErr bool
+	}
+
+	// File system with multiple problems
+	mapFs := fstest.MapFS{
+		"schema/1_init.up.sql":     {Data: []byte("PRAGMA user_version = 1")},
+		"schema/1_init.down.sql":   {Data: []byte("PRAGMA user_version = 0")},
+		"schema/2_tbl.up.sql":      {Data: []byte("PRAGMA user_version = 2")},
+		"schema/2_other.up.sql":    {Data: []byte("PRAGMA user_version = 2")},
+		"schema/2_tbl.down.sql":    {Data: []byte(" \n\t")},
+		"schema/3_data.up.sql":     {Data: []byte("PRAGMA user_version = 3")},
+		"schema/4_data.down.sql":   {Data: []byte("PRAGMA user_version = 3")},
+		"schema/nested/5_a.up.sql": {Data: []byte("PRAGMA user_version = 5")},
+	}
+
+	tests := []testCase{
+		{fsNormalTestV3, dirNormalTestV3, []ProblemKind{}, false},
+		{fsOtherTest, "test_schema/with_others", []ProblemKind{ProblemStrayFile}, false},
+		{fsSkippedTest, "test_schema/skipped", []ProblemKind{ProblemGap}, false},
+		{mapFs, "schema", []ProblemKind{
+			ProblemNestedDir,
+			ProblemEmptyScript, ProblemDuplicate,
+			ProblemMissingDown,
+			ProblemMissingUp,
+		}, false},
+
+		{fsNormalTestV3, "abc", nil, true},
+	}
+
+	for idx, tt := range tests {
+		got, err := ValidateMigrations(tt.fs, tt.dir)
+
+		if tt.wantErr {
+			assert.NotNilf(t, err, "Case %d: err should be non-nil", idx)
+			continue
+		}
+
+		assert.Nilf(t, err, "Case %d: err should be nil, but %v", idx, err)
+		assert.ElementsMatchf(t, tt.want, problemKinds(got), "Case %d: unexpected problems: %v", idx, got)
+	}
+}
+
+func TestValidateMigrationsDetail(t *testing.T) {
+	got, err := ValidateMigrations(fsSkippedTest, "test_schema/skipped")
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, []MigrationProblem{
+		{ProblemGap, 2, "", "version 2 is missing"},
+	}, got)
+}
+
+// Test migration refused when strict migration enabled.
+func TestStrictMigrations(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Case: Invalid migration directory
+	db := New(
+		DbPath(filepath.Join(tmpDir, "strict.db")),
+		Migrate(fsSkippedTest, "test_schema/skipped"),
+		StrictMigrations(),
+	)
+	db.Connect()
+	defer db.Close()
+
+	_, err := db.Migrate()
+	assert.ErrorIs(t, err, ErrInvalidMigration)
+
+	var valErr *ValidationError
+	assert.ErrorAs(t, err, &valErr)
+
+	// Case: Valid migration directory
+	db2 := New(
+		DbPath(filepath.Join(tmpDir, "strict2.db")),
+		Migrate(fsNormalTestV3, dirNormalTestV3),
+		StrictMigrations(),
+	)
+	db2.Connect()
+	defer db2.Close()
+
+	_, err = db2.Migrate()
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+}
@@ -1,8 +1,10 @@
 package lazydb
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 // Container for create & execute prepared statements. Read only after creation.
@@ -12,32 +14,72 @@ type ParamQuery struct {
 }
 
 // Create ParamQuery by given parameters. Value are not able to modify after creation.
+//
+// Named parameters ":name", "@name" & "$name" can be bound by sql.Named,
+// or map[string]any which is expanded into sql.Named.
 func Param(query string, args ...any) ParamQuery {
 	return ParamQuery{
 		Query: query,
-		Args:  args,
+		Args:  expandArgs(args),
 	}
 }
 
-// Return filled version of query.
-func (p *ParamQuery) Filled() string {
+// Return filled version of query, which can be pasted into sqlite3 shell for debugging.
+//
+// Error will be returned if any argument cannot be formatted as SQL literal.
+func (p *ParamQuery) Filled() (string, error) {
 	return asFilledQuery(p.Query, p.Args...)
 }
 
 // ----------------------------------------------------------------
 
-// Execute given query, by prepared statement.
+// Execute given query, by cached prepared statement.
+// Named parameters can be bound by sql.Named or map[string]any, same as Param().
+//
+// If retry policy is set, the query will be retried when database is busy.
 func (l *LazyDB) Exec(query string, args ...any) (sql.Result, error) {
+	return l.ExecContext(context.Background(), query, args...)
+}
+
+// Execute given query with context, by prepared statement.
+func (l *LazyDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	if l.db == nil {
 		return nil, ErrNilDatabase
 	}
+	if l.readOnly {
+		return nil, ErrReadOnly
+	}
 
-	stmt, err := l.db.Prepare(query)
-	if err != nil {
-		return nil, err
+	// Expand map of named parameters
+	args = expandArgs(args)
+
+	// Called inside transaction
+	if tx := l.activeTx(ctx); tx != nil {
+		return tx.ExecContext(ctx, query, args...)
 	}
 
-	return stmt.Exec(args...)
+	var result sql.Result
+	err := l.retry.run(ctx, func() error {
+		unlock, err := l.lockWriter(ctx)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		start := time.Now()
+		cs, err := l.stmts.get(ctx, l.db, query)
+		if err != nil {
+			l.tracer.trace(ctx, QueryOpExec, query, args, start, nil, err)
+			return err
+		}
+		defer l.stmts.release(cs)
+
+		result, err = cs.stmt.ExecContext(ctx, args...)
+		l.tracer.trace(ctx, QueryOpExec, query, args, start, result, err)
+		return err
+	})
+
+	return result, err
 }
 
 // Execute given query, by prepared statement & transaction.
@@ -45,18 +87,53 @@ func (l *LazyDB) Exec(query string, args ...any) (sql.Result, error) {
 // Any failed query will cause a rollback, and return nil []sql.Result.
 // Only all queries successful will return valid sql.Result slices.
 func (l *LazyDB) ExecMultiple(pQueries []ParamQuery) ([]sql.Result, error) {
+	return l.ExecMultipleContext(context.Background(), pQueries)
+}
+
+// Execute given query with context, by prepared statement & transaction.
+//
+// Any failed query or cancelled context will cause a rollback, and return nil []sql.Result.
+// Only all queries successful will return valid sql.Result slices.
+//
+// If retry policy is set, the whole transaction will be retried when database is busy.
+func (l *LazyDB) ExecMultipleContext(ctx context.Context, pQueries []ParamQuery) ([]sql.Result, error) {
 	if l.db == nil {
 		return nil, ErrNilDatabase
 	}
+	if l.readOnly {
+		return nil, ErrReadOnly
+	}
 
 	if pQueries == nil {
 		return nil, ErrEmptyStmt
 	}
 
+	// Called inside transaction, use savepoint instead
+	if tx := l.activeTx(ctx); tx != nil {
+		return tx.execMultiple(ctx, pQueries)
+	}
+
+	var results []sql.Result
+	err := l.retry.run(ctx, func() error {
+		unlock, err := l.lockWriter(ctx)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		results, err = l.execMultiple(ctx, pQueries)
+		return err
+	})
+
+	return results, err
+}
+
+// Execute given queries in a single transaction, which any failure will cause a rollback.
+func (l *LazyDB) execMultiple(ctx context.Context, pQueries []ParamQuery) ([]sql.Result, error) {
 	results := make([]sql.Result, 0)
 
 	// Begin transaction
-	tx, err := l.db.Begin()
+	tx, err := l.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -64,14 +141,18 @@ func (l *LazyDB) ExecMultiple(pQueries []ParamQuery) ([]sql.Result, error) {
 
 	// Exec query with prepare statement
 	for _, query := range pQueries {
-		stmt, err := tx.Prepare(query.Query)
+		start := time.Now()
+		stmt, err := tx.PrepareContext(ctx, query.Query)
 		if err != nil {
-			return nil, fmt.Errorf("failed to prepare '%s': %v", query.Query, err)
+			l.tracer.trace(ctx, QueryOpExecMultiple, query.Query, query.Args, start, nil, err)
+			return nil, fmt.Errorf("failed to prepare '%s': %w", query.Query, err)
 		}
 
-		result, err := stmt.Exec(query.Args...)
+		result, err := stmt.ExecContext(ctx, expandArgs(query.Args)...)
+		stmt.Close()
+		l.tracer.trace(ctx, QueryOpExecMultiple, query.Query, query.Args, start, result, err)
 		if err != nil {
-			return nil, fmt.Errorf("failed to exec '%s' with (%v): %v", query.Query, query.Args, err)
+			return nil, fmt.Errorf("failed to exec '%s' with (%v): %w", query.Query, query.Args, err)
 		}
 
 		// Record result of query
@@ -90,28 +171,68 @@ func (l *LazyDB) ExecMultiple(pQueries []ParamQuery) ([]sql.Result, error) {
 
 // Wrapper for Query() function, using prepared statement.
 func (l *LazyDB) Query(query string, args ...any) (*sql.Rows, error) {
+	return l.QueryContext(context.Background(), query, args...)
+}
+
+// Wrapper for QueryContext() function, using prepared statement.
+func (l *LazyDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	if l.db == nil {
 		return nil, ErrNilDatabase
 	}
 
-	stmt, err := l.db.Prepare(query)
+	// Expand map of named parameters
+	args = expandArgs(args)
+
+	// Called inside transaction
+	if tx := l.activeTx(ctx); tx != nil {
+		return tx.QueryContext(ctx, query, args...)
+	}
+
+	db, stmts := l.queryPool()
+
+	start := time.Now()
+	cs, err := stmts.get(ctx, db, query)
 	if err != nil {
+		l.tracer.trace(ctx, QueryOpQuery, query, args, start, nil, err)
 		return nil, err
 	}
+	defer stmts.release(cs)
 
-	return stmt.Query(args...)
+	rows, err := cs.stmt.QueryContext(ctx, args...)
+	l.tracer.trace(ctx, QueryOpQuery, query, args, start, nil, err)
+	return rows, err
 }
 
 // Wrapper for QueryRow() function, using prepared statement.
 func (l *LazyDB) QueryRow(query string, args ...any) (*sql.Row, error) {
+	return l.QueryRowContext(context.Background(), query, args...)
+}
+
+// Wrapper for QueryRowContext() function, using prepared statement.
+func (l *LazyDB) QueryRowContext(ctx context.Context, query string, args ...any) (*sql.Row, error) {
 	if l.db == nil {
 		return nil, ErrNilDatabase
 	}
 
-	stmt, err := l.db.Prepare(query)
+	// Expand map of named parameters
+	args = expandArgs(args)
+
+	// Called inside transaction
+	if tx := l.activeTx(ctx); tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+
+	db, stmts := l.queryPool()
+
+	start := time.Now()
+	cs, err := stmts.get(ctx, db, query)
 	if err != nil {
+		l.tracer.trace(ctx, QueryOpQueryRow, query, args, start, nil, err)
 		return nil, err
 	}
+	defer stmts.release(cs)
 
-	return stmt.QueryRow(args...), nil
+	row := cs.stmt.QueryRowContext(ctx, args...)
+	l.tracer.trace(ctx, QueryOpQueryRow, query, args, start, nil, row.Err())
+	return row, nil
 }
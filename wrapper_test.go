@@ -1,6 +1,7 @@
 package lazydb
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"path/filepath"
@@ -261,3 +262,102 @@ func TestMixedExecMultiple(t *testing.T) {
 
 	assert.EqualValuesf(t, 2, ct, "<ExecMultiple Insert> unexpected value: %v", ct)
 }
+
+// Ensure context variants respect cancelled context.
+func TestWrapperCancelledContext(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "ctx.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = db.QueryContext(ctx, "SELECT val FROM test_table")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = db.ExecContext(ctx, "DELETE FROM test_table")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = db.ExecMultipleContext(ctx, []ParamQuery{{"DELETE FROM test_table", nil}})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Nothing should be deleted
+	var ct int
+	db.DB().QueryRow("SELECT COUNT(*) FROM test_table").Scan(&ct)
+	assert.EqualValuesf(t, 2, ct, "Rows should not be deleted by cancelled context")
+}
+
+// Test ExecMultiple with named parameters.
+func TestExecMultipleNamed(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "named.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	_, err = db.ExecMultiple([]ParamQuery{
+		Param("INSERT INTO test_table (content, val) VALUES (:content, :val)", sql.Named("content", "ghi"), sql.Named("val", 1)),
+		Param("INSERT INTO test_table (content, val) VALUES (@content, @val)", map[string]any{"content": "jkl", "val": 2}),
+		{"INSERT INTO test_table (content, val) VALUES ($content, $val)", []any{map[string]any{"content": "mno", "val": 3}}},
+	})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	var sum int
+	err = db.DB().QueryRow("SELECT SUM(val) FROM test_table WHERE content IN ('ghi', 'jkl', 'mno')").Scan(&sum)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 6, sum)
+}
+
+// Test Exec, Query & QueryRow with named parameters by map.
+func TestWrapperNamedMap(t *testing.T) {
+	db := New(DbPath(filepath.Join(t.TempDir(), "named_map.db")))
+	err := db.Connect()
+	if err != nil {
+		t.Fatal("Failed to connect: ", err)
+	}
+	defer db.Close()
+	createDummyTable(db.DB())
+
+	_, err = db.Exec("INSERT INTO test_table (content, val) VALUES (:content, :val)", map[string]any{"content": "ghi", "val": 7})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+
+	var val int
+	row, err := db.QueryRow("SELECT val FROM test_table WHERE content = :content", map[string]any{"content": "ghi"})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	err = row.Scan(&val)
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 7, val)
+
+	rows, err := db.Query("SELECT content FROM test_table WHERE val = @val", map[string]any{"val": 7})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	var contents []string
+	for rows.Next() {
+		var c string
+		rows.Scan(&c)
+		contents = append(contents, c)
+	}
+	rows.Close()
+	assert.EqualValues(t, []string{"ghi"}, contents)
+
+	// Inside transaction
+	err = db.WithTx(context.Background(), func(tx *Tx) error {
+		_, err := tx.Exec("UPDATE test_table SET val = $val WHERE content = $content", map[string]any{"content": "ghi", "val": 8})
+		if err != nil {
+			return err
+		}
+
+		row, err := tx.QueryRow("SELECT val FROM test_table WHERE content = :content", map[string]any{"content": "ghi"})
+		if err != nil {
+			return err
+		}
+		return row.Scan(&val)
+	})
+	assert.Nilf(t, err, "Unexpected error: %v", err)
+	assert.EqualValues(t, 8, val)
+}